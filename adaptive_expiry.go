@@ -0,0 +1,93 @@
+package grpool
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// 依照負載自動調整過期時間的設定
+type AdaptiveExpiry struct {
+	// 過期時間的範圍
+	MinExpiry time.Duration
+	MaxExpiry time.Duration
+
+	// 每次清理最多回收的 Worker 數量範圍，MinReclaim 至少為 1，MaxReclaim 為 0 代表沒有上限
+	MinReclaim int
+	MaxReclaim int
+}
+
+// EWMA 中新樣本的權重
+const adaptiveSmoothing = 0.3
+
+// 觀察任務到達速度與閒置 Worker 數量，調整過期時間與每次回收的數量
+type adaptiveCleaner struct {
+	cfg AdaptiveExpiry
+
+	// 上一次清理之後提交的任務數量
+	arrivals int64
+
+	// 每次清理之間平均提交的任務數量
+	rate float64
+
+	// 目前的過期時間
+	expiry int64
+
+	sampled bool
+}
+
+func newAdaptiveCleaner(cfg AdaptiveExpiry) *adaptiveCleaner {
+	if cfg.MinReclaim <= 0 {
+		cfg.MinReclaim = 1
+	}
+	if cfg.MaxReclaim > 0 && cfg.MaxReclaim < cfg.MinReclaim {
+		cfg.MaxReclaim = cfg.MinReclaim
+	}
+	return &adaptiveCleaner{
+		cfg:    cfg,
+		expiry: int64(cfg.MaxExpiry),
+	}
+}
+
+// 記錄提交的任務
+func (a *adaptiveCleaner) arrive() {
+	atomic.AddInt64(&a.arrivals, 1)
+}
+
+// 獲取目前的過期時間
+func (a *adaptiveCleaner) currentExpiry() time.Duration {
+	return time.Duration(atomic.LoadInt64(&a.expiry))
+}
+
+// 每次清理時呼叫，依照閒置的 Worker 數量返回這次使用的過期時間與最多回收的數量，
+// 閒置的 Worker 比平均需求多時縮短過期時間並回收多餘的部分，反之則延長過期時間並減少回收
+func (a *adaptiveCleaner) tick(idle int) (time.Duration, int) {
+	arrivals := float64(atomic.SwapInt64(&a.arrivals, 0))
+	if a.sampled {
+		a.rate = a.rate*(1-adaptiveSmoothing) + arrivals*adaptiveSmoothing
+	} else {
+		a.rate, a.sampled = arrivals, true
+	}
+
+	expiry := a.currentExpiry()
+	reclaim := a.cfg.MinReclaim
+	if surplus := float64(idle) - a.rate; surplus > 0 {
+		expiry /= 2
+		if s := int(surplus); s > reclaim {
+			reclaim = s
+		}
+		if a.cfg.MaxReclaim > 0 && reclaim > a.cfg.MaxReclaim {
+			reclaim = a.cfg.MaxReclaim
+		}
+	} else {
+		expiry *= 2
+	}
+
+	if expiry < a.cfg.MinExpiry {
+		expiry = a.cfg.MinExpiry
+	}
+	if expiry > a.cfg.MaxExpiry {
+		expiry = a.cfg.MaxExpiry
+	}
+	atomic.StoreInt64(&a.expiry, int64(expiry))
+	return expiry, reclaim
+}
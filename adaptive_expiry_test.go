@@ -0,0 +1,64 @@
+package grpool
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdaptiveCleanerTick(t *testing.T) {
+	a := newAdaptiveCleaner(AdaptiveExpiry{
+		MinExpiry:  time.Second,
+		MaxExpiry:  8 * time.Second,
+		MaxReclaim: 50,
+	})
+	assert.Equal(t, 8*time.Second, a.currentExpiry())
+
+	// 閒置的 Worker 比需求多，縮短過期時間並回收多餘的部分
+	for i := 0; i < 10; i++ {
+		a.arrive()
+	}
+	expiry, reclaim := a.tick(30)
+	assert.Equal(t, 4*time.Second, expiry)
+	assert.Equal(t, 20, reclaim)
+
+	expiry, reclaim = a.tick(1000)
+	assert.Equal(t, 2*time.Second, expiry)
+	assert.Equal(t, 50, reclaim, "reclaim should not exceed MaxReclaim")
+
+	_, _ = a.tick(1000)
+	expiry, _ = a.tick(1000)
+	assert.Equal(t, time.Second, expiry, "expiry should not go below MinExpiry")
+
+	// 需求比閒置的 Worker 多，延長過期時間並減少回收
+	for i := 0; i < 1000; i++ {
+		a.arrive()
+	}
+	expiry, reclaim = a.tick(0)
+	assert.Equal(t, 2*time.Second, expiry)
+	assert.Equal(t, 1, reclaim)
+	assert.Equal(t, expiry, a.currentExpiry())
+}
+
+func TestGrPoolWithAdaptiveExpiry(t *testing.T) {
+	p, _ := NewPool(size, WithExpiryDuration(20*time.Millisecond), WithAdaptiveExpiry(AdaptiveExpiry{
+		MinExpiry:  10 * time.Millisecond,
+		MaxExpiry:  time.Second,
+		MaxReclaim: 10,
+	}))
+	defer p.Release()
+	assert.Equal(t, time.Second, p.Expiry())
+
+	_ = p.Warmup(100)
+	time.Sleep(150 * time.Millisecond)
+	assert.Less(t, p.Expiry(), time.Second, "expiry should shrink while workers are idle")
+
+	// 每次最多回收 10 個，不會一次全部清除
+	running := p.Running()
+	assert.Greater(t, running, 0)
+	assert.Less(t, running, 100)
+
+	_, err := NewPool(size, WithAdaptiveExpiry(AdaptiveExpiry{MinExpiry: time.Second, MaxExpiry: time.Millisecond}))
+	assert.ErrorIs(t, err, ErrInvalidPoolExpiry)
+}
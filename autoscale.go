@@ -0,0 +1,218 @@
+package grpool
+
+import (
+	"context"
+	"math"
+	"sync/atomic"
+	"time"
+)
+
+// 時鐘，測試時可以替換成假的時鐘
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// 自動調整 Pool 容量的設定
+type Autoscale struct {
+	// 容量的範圍
+	Min int
+	Max int
+
+	// 取樣的頻率，預設為 1 秒
+	Interval time.Duration
+
+	// 依照取樣結果決定容量，預設為 TargetUtilizationPolicy{Target: 0.8}
+	Policy ScalePolicy
+
+	// 預設為真實的時鐘
+	Clock Clock
+}
+
+// 每次取樣的結果
+type ScaleStats struct {
+	// 目前的容量
+	Capacity int
+
+	// 正在執行的 Worker 數量，包含閒置的 Worker
+	Running int
+
+	// 閒置的 Worker 數量
+	Idle int
+
+	// 等待 Worker 的呼叫數量
+	Waiting int
+
+	// 上一次取樣之後完成的任務數量
+	Completed int
+
+	// 上一次取樣之後完成的任務平均執行時間
+	Latency time.Duration
+
+	// 距離上一次取樣的時間
+	Elapsed time.Duration
+}
+
+// 忙碌中的 Worker 數量
+func (s ScaleStats) Busy() int {
+	return s.Running - s.Idle
+}
+
+// 依照取樣結果返回想要的容量，超出 [Min, Max] 的部分會被限制
+type ScalePolicy interface {
+	Scale(stats ScaleStats) int
+}
+
+// 讓忙碌中的 Worker 與等待中的呼叫佔容量的比例維持在 Target
+type TargetUtilizationPolicy struct {
+	// 目標使用率，範圍 (0, 1]
+	Target float64
+}
+
+func (tp TargetUtilizationPolicy) Scale(stats ScaleStats) int {
+	target := tp.Target
+	if target <= 0 || target > 1 {
+		target = 1
+	}
+	return int(math.Ceil(float64(stats.Busy()+stats.Waiting) / target))
+}
+
+// 加性增加、乘性減少: 有呼叫在等待時容量加上 Increase，
+// 平均執行時間超過 LatencyThreshold 或使用率低於一半時容量乘上 Decrease
+type AIMDPolicy struct {
+	// 每次增加的容量，預設為 1
+	Increase int
+
+	// 每次減少時乘上的比例，範圍 (0, 1)，預設為 0.5
+	Decrease float64
+
+	// 平均執行時間超過這個值代表下游壅塞，0 代表不考慮執行時間
+	LatencyThreshold time.Duration
+}
+
+func (ap AIMDPolicy) Scale(stats ScaleStats) int {
+	increase, decrease := ap.Increase, ap.Decrease
+	if increase <= 0 {
+		increase = 1
+	}
+	if decrease <= 0 || decrease >= 1 {
+		decrease = 0.5
+	}
+
+	switch {
+	case ap.LatencyThreshold > 0 && stats.Latency > ap.LatencyThreshold:
+		return int(float64(stats.Capacity) * decrease)
+	case stats.Waiting > 0:
+		return stats.Capacity + increase
+	case stats.Busy()*2 < stats.Capacity:
+		return int(math.Ceil(float64(stats.Capacity) * decrease))
+	}
+	return stats.Capacity
+}
+
+// 統計任務的執行時間
+type latencyStats struct {
+	count int64
+	total int64
+}
+
+func (ls *latencyStats) observe(d time.Duration) {
+	atomic.AddInt64(&ls.count, 1)
+	atomic.AddInt64(&ls.total, int64(d))
+}
+
+// 返回上一次呼叫之後完成的任務數量與平均執行時間
+func (ls *latencyStats) swap() (int, time.Duration) {
+	count := atomic.SwapInt64(&ls.count, 0)
+	total := atomic.SwapInt64(&ls.total, 0)
+	if count == 0 {
+		return 0, 0
+	}
+	return int(count), time.Duration(total / count)
+}
+
+// 調整 Pool 容量的控制器
+type autoscaler struct {
+	cfg  Autoscale
+	last time.Time
+}
+
+func newAutoscaler(cfg Autoscale) *autoscaler {
+	if cfg.Interval <= 0 {
+		cfg.Interval = time.Second
+	}
+	if cfg.Policy == nil {
+		cfg.Policy = TargetUtilizationPolicy{Target: 0.8}
+	}
+	if cfg.Clock == nil {
+		cfg.Clock = realClock{}
+	}
+	return &autoscaler{cfg: cfg, last: cfg.Clock.Now()}
+}
+
+// 開啟一個 goroutine 定時調整容量
+func (p *Pool) goAutoscale() {
+	if p.autoscaler == nil {
+		return
+	}
+
+	var ctx context.Context
+	ctx, p.stopAutoscale = context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	p.autoscaleStopped = stopped
+	go func() {
+		defer close(stopped)
+		p.runAutoscale(ctx)
+	}()
+}
+
+func (p *Pool) runAutoscale(ctx context.Context) {
+	a := p.autoscaler
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-a.cfg.Clock.After(a.cfg.Interval):
+			if p.IsClosed() {
+				return
+			}
+			p.autoscale()
+		}
+	}
+}
+
+// 取樣並依照 ScalePolicy 調整容量
+func (p *Pool) autoscale() {
+	a := p.autoscaler
+
+	now := a.cfg.Clock.Now()
+	stats := ScaleStats{
+		Capacity: p.Cap(),
+		Running:  p.Running(),
+		Waiting:  p.Waiting(),
+		Elapsed:  now.Sub(a.last),
+	}
+	a.last = now
+	stats.Completed, stats.Latency = p.latency.swap()
+
+	p.lock.Lock()
+	stats.Idle = p.workers.len()
+	p.lock.Unlock()
+	if stats.Idle > stats.Running {
+		stats.Idle = stats.Running
+	}
+
+	size := a.cfg.Policy.Scale(stats)
+	if size < a.cfg.Min {
+		size = a.cfg.Min
+	}
+	if size > a.cfg.Max {
+		size = a.cfg.Max
+	}
+	p.Tune(size)
+}
@@ -0,0 +1,162 @@
+package grpool
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// 手動推進時間的時鐘
+type fakeClock struct {
+	lock    sync.Mutex
+	now     time.Time
+	waiters []fakeTimer
+}
+
+type fakeTimer struct {
+	at time.Time
+	ch chan time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Unix(0, 0)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	ch := make(chan time.Time, 1)
+	c.waiters = append(c.waiters, fakeTimer{at: c.now.Add(d), ch: ch})
+	return ch
+}
+
+// 推進時間並觸發到期的 timer
+func (c *fakeClock) Advance(d time.Duration) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.now = c.now.Add(d)
+	waiters := c.waiters[:0]
+	for _, w := range c.waiters {
+		if w.at.After(c.now) {
+			waiters = append(waiters, w)
+			continue
+		}
+		w.ch <- c.now
+	}
+	c.waiters = waiters
+}
+
+// 等待至少 n 個 timer 在等待中
+func (c *fakeClock) BlockUntil(t *testing.T, n int) {
+	assert.Eventually(t, func() bool {
+		c.lock.Lock()
+		defer c.lock.Unlock()
+		return len(c.waiters) >= n
+	}, time.Second, time.Millisecond)
+}
+
+func TestTargetUtilizationPolicy(t *testing.T) {
+	policy := TargetUtilizationPolicy{Target: 0.5}
+	assert.Equal(t, 20, policy.Scale(ScaleStats{Capacity: 10, Running: 10, Waiting: 0}))
+	assert.Equal(t, 30, policy.Scale(ScaleStats{Capacity: 10, Running: 10, Waiting: 5}))
+	assert.Equal(t, 8, policy.Scale(ScaleStats{Capacity: 10, Running: 10, Idle: 6}))
+}
+
+func TestAIMDPolicy(t *testing.T) {
+	policy := AIMDPolicy{Increase: 2, Decrease: 0.5, LatencyThreshold: 100 * time.Millisecond}
+	assert.Equal(t, 12, policy.Scale(ScaleStats{Capacity: 10, Running: 10, Waiting: 3}))
+	assert.Equal(t, 5, policy.Scale(ScaleStats{Capacity: 10, Running: 10, Waiting: 3, Latency: time.Second}))
+	assert.Equal(t, 5, policy.Scale(ScaleStats{Capacity: 10, Running: 10, Idle: 8}))
+	assert.Equal(t, 10, policy.Scale(ScaleStats{Capacity: 10, Running: 10, Idle: 2}))
+}
+
+func TestGrPoolWithAutoscale(t *testing.T) {
+	clock := newFakeClock()
+	p, err := NewPool(2, WithAutoscale(Autoscale{
+		Min:      2,
+		Max:      20,
+		Interval: time.Second,
+		Policy:   AIMDPolicy{Increase: 2},
+		Clock:    clock,
+	}))
+	assert.NoError(t, err)
+	defer p.Release()
+
+	// 佔滿 Worker，讓其他呼叫等待
+	release := occupyPool(p, 2)
+	var wg sync.WaitGroup
+	block := make(chan struct{})
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			_ = p.Schedule(func() {
+				<-block
+				wg.Done()
+			})
+		}()
+	}
+	assert.Eventually(t, func() bool { return p.Waiting() == 3 }, time.Second, time.Millisecond)
+
+	// 有呼叫在等待，容量增加
+	clock.BlockUntil(t, 1)
+	clock.Advance(time.Second)
+	assert.Eventually(t, func() bool { return p.Cap() == 4 }, time.Second, time.Millisecond)
+	assert.Eventually(t, func() bool { return p.Waiting() == 1 }, time.Second, time.Millisecond)
+
+	clock.BlockUntil(t, 1)
+	clock.Advance(time.Second)
+	assert.Eventually(t, func() bool { return p.Cap() == 6 }, time.Second, time.Millisecond)
+	assert.Eventually(t, func() bool { return p.Waiting() == 0 }, time.Second, time.Millisecond)
+
+	// 全部閒置，容量減少但不會低於 Min
+	release()
+	close(block)
+	wg.Wait()
+	for _, want := range []int{3, 2, 2} {
+		clock.BlockUntil(t, 1)
+		clock.Advance(time.Second)
+		want := want
+		assert.Eventually(t, func() bool { return p.Cap() == want }, time.Second, time.Millisecond)
+	}
+
+	_, err = NewPool(2, WithAutoscale(Autoscale{Min: 10, Max: 5}))
+	assert.ErrorIs(t, err, ErrInvalidAutoscale)
+}
+
+func TestGrPoolTune(t *testing.T) {
+	p, _ := NewPool(1)
+	defer p.Release()
+
+	release := occupyPool(p, 1)
+	defer release()
+
+	done := make(chan struct{})
+	go func() {
+		_ = p.Schedule(func() {})
+		close(done)
+	}()
+	assert.Eventually(t, func() bool { return p.Waiting() == 1 }, time.Second, time.Millisecond)
+
+	// 增加容量會喚醒等待中的呼叫
+	p.Tune(2)
+	assert.Equal(t, 2, p.Cap())
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Tune should wake up blocked callers")
+	}
+
+	// 無限容量的 Pool 不能調整
+	p2, _ := NewPool(-1)
+	defer p2.Release()
+	p2.Tune(10)
+	assert.Equal(t, -1, p2.Cap())
+}
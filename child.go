@@ -0,0 +1,193 @@
+package grpool
+
+import (
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// 子 Pool，任務在父 Pool 的 Worker 上執行，同時受到自己與父 Pool 容量的限制
+type ChildPool struct {
+	parent *Pool
+
+	// 容量，-1 代表只受父 Pool 容量限制
+	capacity int
+
+	// 已經交給父 Pool 但還沒完成的任務數量
+	running int
+
+	// 狀態
+	state int32
+
+	lock sync.Mutex
+
+	// 達到容量時等待任務完成
+	cond *sync.Cond
+
+	options *Options
+}
+
+// 父 Pool 底下的子 Pool
+type childGroup struct {
+	lock  sync.Mutex
+	pools map[*ChildPool]struct{}
+}
+
+// 建立子 Pool，size 小於等於 0 代表只受父 Pool 容量限制，只支援 Nonblocking 與 PanicHandler 設定，
+// 傳入其他設定時返回 ErrInvalidChildOption，父 Pool 被 Release 時子 Pool 也會一起被關閉，Reboot 之後需要重新建立
+func (p *Pool) NewChild(size int, options ...Option) (*ChildPool, error) {
+	opts := loadOptions(options...)
+	// 其他設定由父 Pool 決定，避免誤以為子 Pool 會套用
+	rest := *opts
+	rest.Nonblocking, rest.PanicHandler = false, nil
+	if !reflect.DeepEqual(rest, Options{}) {
+		return nil, ErrInvalidChildOption
+	}
+
+	if size <= 0 {
+		size = -1
+	}
+	c := &ChildPool{
+		parent:   p,
+		capacity: size,
+		options:  opts,
+	}
+	c.cond = sync.NewCond(&c.lock)
+
+	p.children.lock.Lock()
+	defer p.children.lock.Unlock()
+	// 在鎖內檢查，避免 Release 之後才加入
+	if err := p.checkAccepting(); err != nil {
+		return nil, err
+	}
+	if p.children.pools == nil {
+		p.children.pools = make(map[*ChildPool]struct{})
+	}
+	p.children.pools[c] = struct{}{}
+	return c, nil
+}
+
+// 關閉所有子 Pool
+func (cg *childGroup) release() {
+	cg.lock.Lock()
+	pools := make([]*ChildPool, 0, len(cg.pools))
+	for c := range cg.pools {
+		pools = append(pools, c)
+	}
+	cg.lock.Unlock()
+
+	for _, c := range pools {
+		c.Release()
+	}
+}
+
+// 將任務交給父 Pool 執行，子 Pool 滿載時 Blocking 模式會等待自己的任務完成，Nonblocking 模式則返回 ErrPoolOverload，
+// 父 Pool 滿載時子 Pool 或父 Pool 其中一個是 Nonblocking 就不等待，返回 ErrPoolOverload
+func (c *ChildPool) Schedule(task func()) error {
+	if task == nil {
+		return ErrLackPoolFunc
+	}
+	if c.IsClosed() {
+		return ErrPoolClosed
+	}
+	if err := c.parent.checkAccepting(); err != nil {
+		return err
+	}
+
+	c.lock.Lock()
+	waited := false
+	for c.capacity != -1 && c.running >= c.capacity {
+		if c.options.Nonblocking {
+			c.lock.Unlock()
+			return ErrPoolOverload
+		}
+		// 等到任務交給父 Pool 之後才不算在等待中，避免父 Pool 的 Drain 提早關閉
+		if !waited {
+			atomic.AddInt32(&c.parent.waiting, 1)
+			waited = true
+		}
+		c.cond.Wait()
+		if c.IsClosed() {
+			c.lock.Unlock()
+			c.parent.doneWaiting()
+			return ErrPoolClosed
+		}
+	}
+	c.running++
+	c.lock.Unlock()
+
+	err := c.parent.dispatch(func() {
+		defer c.done()
+		if ph := c.options.PanicHandler; ph != nil {
+			defer func() {
+				if r := recover(); r != nil {
+					ph(r)
+				}
+			}()
+		}
+		task()
+	}, !c.options.Nonblocking && !c.parent.currentOptions().Nonblocking)
+	if err != nil {
+		c.done()
+	}
+	if waited {
+		c.parent.doneWaiting()
+	}
+	return err
+}
+
+// 任務完成後釋放容量，並喚醒等待中的任務
+func (c *ChildPool) done() {
+	c.lock.Lock()
+	c.running--
+	c.cond.Signal()
+	c.lock.Unlock()
+}
+
+// 獲取子 Pool 容量
+func (c *ChildPool) Cap() int {
+	return c.capacity
+}
+
+// 獲取子 Pool 正在執行的任務數量
+func (c *ChildPool) Running() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.running
+}
+
+// 獲取還能執行的任務數量，同時受到父 Pool 剩下的容量限制，-1 代表沒有限制
+func (c *ChildPool) Free() int {
+	free := c.parent.Free()
+	if c.capacity == -1 {
+		return free
+	}
+	c.lock.Lock()
+	own := c.capacity - c.running
+	c.lock.Unlock()
+	if free == -1 || own < free {
+		return own
+	}
+	return free
+}
+
+// 判斷子 Pool 或父 Pool 是否被關閉
+func (c *ChildPool) IsClosed() bool {
+	return atomic.LoadInt32(&c.state) == CLOSED || c.parent.IsClosed()
+}
+
+// 關閉子 Pool，已經交給父 Pool 的任務不受影響
+func (c *ChildPool) Release() {
+	if !atomic.CompareAndSwapInt32(&c.state, OPENED, CLOSED) {
+		return
+	}
+
+	c.parent.children.lock.Lock()
+	delete(c.parent.children.pools, c)
+	c.parent.children.lock.Unlock()
+
+	// 喚醒等待中的任務
+	c.lock.Lock()
+	c.cond.Broadcast()
+	c.lock.Unlock()
+}
@@ -0,0 +1,167 @@
+package grpool
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChildPool(t *testing.T) {
+	p, _ := NewPool(4, WithNonblocking(true))
+	defer p.Release()
+
+	a, err := p.NewChild(2, WithNonblocking(true))
+	assert.NoError(t, err)
+	b, err := p.NewChild(3, WithNonblocking(true))
+	assert.NoError(t, err)
+
+	block := make(chan struct{})
+	var started sync.WaitGroup
+	started.Add(2)
+	for i := 0; i < 2; i++ {
+		assert.NoError(t, a.Schedule(func() {
+			started.Done()
+			<-block
+		}))
+	}
+	started.Wait()
+
+	// 子 Pool 自己的容量
+	assert.ErrorIs(t, a.Schedule(demoFunc), ErrPoolOverload)
+	assert.Equal(t, 2, a.Running())
+	assert.Equal(t, 0, a.Free())
+
+	// 父 Pool 只剩下 2 個容量，b 雖然還有 3 個容量也只能用 2 個
+	assert.Equal(t, 2, b.Free())
+	started.Add(2)
+	for i := 0; i < 2; i++ {
+		assert.NoError(t, b.Schedule(func() {
+			started.Done()
+			<-block
+		}))
+	}
+	started.Wait()
+	assert.ErrorIs(t, b.Schedule(demoFunc), ErrPoolOverload)
+	assert.Equal(t, 2, b.Running())
+	assert.Equal(t, 3, b.Cap())
+
+	close(block)
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, 0, a.Running())
+	assert.Equal(t, 0, b.Running())
+}
+
+func TestChildPoolBlocking(t *testing.T) {
+	p, _ := NewPool(10)
+	defer p.Release()
+	c, _ := p.NewChild(1)
+
+	block := make(chan struct{})
+	assert.NoError(t, c.Schedule(func() { <-block }))
+
+	done := make(chan struct{})
+	go func() {
+		assert.NoError(t, c.Schedule(func() { close(done) }))
+	}()
+	select {
+	case <-done:
+		t.Fatal("task should wait for the child capacity")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(block)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("task should run after the child has free capacity")
+	}
+}
+
+func TestChildPoolRelease(t *testing.T) {
+	p, _ := NewPool(10)
+	c, _ := p.NewChild(1)
+
+	block := make(chan struct{})
+	defer close(block)
+	assert.NoError(t, c.Schedule(func() { <-block }))
+
+	waiting := make(chan error, 1)
+	go func() {
+		waiting <- c.Schedule(demoFunc)
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	// 父 Pool 被關閉時子 Pool 也會被關閉
+	p.Release()
+	assert.ErrorIs(t, <-waiting, ErrPoolClosed)
+	assert.True(t, c.IsClosed())
+
+	p.Reboot()
+	defer p.Release()
+	assert.True(t, c.IsClosed())
+	assert.ErrorIs(t, c.Schedule(demoFunc), ErrPoolClosed)
+
+	_, err := p.NewChild(1)
+	assert.NoError(t, err)
+}
+
+func TestChildPoolDrainParent(t *testing.T) {
+	p, _ := NewPool(10)
+	c, _ := p.NewChild(1)
+	block := make(chan struct{})
+	assert.NoError(t, c.Schedule(func() { <-block }))
+
+	// 等待子 Pool 容量的呼叫計入父 Pool 的 Waiting
+	ran := make(chan struct{})
+	blocked := make(chan error, 1)
+	go func() {
+		blocked <- c.Schedule(func() { close(ran) })
+	}()
+	assert.Eventually(t, func() bool { return p.Waiting() == 1 }, time.Second, time.Millisecond)
+
+	p.Drain()
+	close(block)
+	assert.NoError(t, <-blocked)
+	select {
+	case <-ran:
+	case <-time.After(time.Second):
+		t.Fatal("task waiting for the child capacity should run")
+	}
+	select {
+	case <-p.Done():
+	case <-time.After(time.Second):
+		t.Fatal("pool should stop after draining")
+	}
+}
+
+func TestChildPoolOptions(t *testing.T) {
+	p, _ := NewPool(1)
+	defer p.Release()
+
+	// 只支援 Nonblocking 與 PanicHandler
+	_, err := p.NewChild(1, WithExpiryDuration(time.Second))
+	assert.ErrorIs(t, err, ErrInvalidChildOption)
+	_, err = p.NewChild(1, WithTaskQueue(1))
+	assert.ErrorIs(t, err, ErrInvalidChildOption)
+	c, err := p.NewChild(2, WithNonblocking(true), WithPanicHandler(func(interface{}) {}))
+	assert.NoError(t, err)
+
+	// Nonblocking 的子 Pool 在 Blocking 的父 Pool 滿載時也不等待
+	block := make(chan struct{})
+	defer close(block)
+	assert.NoError(t, p.Schedule(func() { <-block }))
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- c.Schedule(demoFunc)
+	}()
+	select {
+	case err := <-errs:
+		assert.ErrorIs(t, err, ErrPoolOverload)
+	case <-time.After(time.Second):
+		t.Fatal("nonblocking child should not wait for the parent")
+	}
+	assert.Equal(t, 0, c.Running())
+}
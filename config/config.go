@@ -0,0 +1,181 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/POABOB/grpool"
+	"gopkg.in/yaml.v3"
+)
+
+var ErrUnknownFormat = errors.New("unknown config format")
+
+// 可以寫成 "1s"、"500ms" 的時間長度，數字則視為秒數
+type Duration time.Duration
+
+func parseDuration(s string) (Duration, error) {
+	s = strings.TrimSpace(s)
+	if secs, err := strconv.ParseFloat(s, 64); err == nil {
+		return Duration(secs * float64(time.Second)), nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, err
+	}
+	return Duration(d), nil
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		// 不是字串時視為秒數
+		s = string(data)
+	}
+	v, err := parseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = v
+	return nil
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *Duration) UnmarshalYAML(node *yaml.Node) error {
+	v, err := parseDuration(node.Value)
+	if err != nil {
+		return err
+	}
+	*d = v
+	return nil
+}
+
+func (d Duration) MarshalYAML() (interface{}, error) {
+	return time.Duration(d).String(), nil
+}
+
+// Pool 的設定，可以從 JSON、YAML 或環境變數載入
+type Config struct {
+	// Pool 容量，小於等於 0 代表沒有上限
+	Size int `json:"size" yaml:"size"`
+
+	// 過期時間，0 代表使用預設值
+	Expiry Duration `json:"expiry" yaml:"expiry"`
+
+	// 是否提前申請空間
+	PreAlloc bool `json:"prealloc" yaml:"prealloc"`
+
+	// Pool 滿載時是否直接返回 ErrPoolOverload
+	Nonblocking bool `json:"nonblocking" yaml:"nonblocking"`
+
+	// 是否停止清理過期的 Worker
+	DisableClear bool `json:"disable_clear" yaml:"disable_clear"`
+}
+
+// 轉換成 NewPool 的參數
+func (c Config) Options() []grpool.Option {
+	return []grpool.Option{
+		grpool.WithExpiryDuration(time.Duration(c.Expiry)),
+		grpool.WithPreAlloc(c.PreAlloc),
+		grpool.WithNonblocking(c.Nonblocking),
+		grpool.WithDisableClear(c.DisableClear),
+	}
+}
+
+// 轉換成 Reconfigure 的參數，容量以外的設定都可以直接套用在執行中的 Pool
+func (c Config) liveOptions() grpool.LiveOptions {
+	return grpool.LiveOptions{
+		ExpiryDuration: time.Duration(c.Expiry),
+		Nonblocking:    c.Nonblocking,
+		DisableClear:   c.DisableClear,
+		PreAlloc:       c.PreAlloc,
+	}
+}
+
+// 檢查設定，返回的錯誤與 NewPool 相同
+func (c Config) Validate() error {
+	return grpool.ValidateOptions(c.Size, c.Options()...)
+}
+
+// 依照設定建立 Pool，options 會套用在設定之後
+func (c Config) NewPool(options ...grpool.Option) (*grpool.Pool, error) {
+	return grpool.NewPool(c.Size, append(c.Options(), options...)...)
+}
+
+// 從 JSON 載入設定
+func LoadJSON(r io.Reader) (Config, error) {
+	var c Config
+	if err := json.NewDecoder(r).Decode(&c); err != nil {
+		return Config{}, err
+	}
+	return c, c.Validate()
+}
+
+// 從 YAML 載入設定
+func LoadYAML(r io.Reader) (Config, error) {
+	var c Config
+	if err := yaml.NewDecoder(r).Decode(&c); err != nil && err != io.EOF {
+		return Config{}, err
+	}
+	return c, c.Validate()
+}
+
+// 依照副檔名從 .json、.yaml 或 .yml 檔案載入設定
+func LoadFile(path string) (Config, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Config{}, err
+	}
+	defer f.Close()
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return LoadJSON(f)
+	case ".yaml", ".yml":
+		return LoadYAML(f)
+	}
+	return Config{}, fmt.Errorf("%w: %s", ErrUnknownFormat, path)
+}
+
+// 從環境變數載入設定: <prefix>SIZE、<prefix>EXPIRY、<prefix>PREALLOC、<prefix>NONBLOCKING、<prefix>DISABLE_CLEAR，
+// 沒有設定的環境變數使用 base 的值
+func LoadEnv(prefix string, base Config) (Config, error) {
+	c := base
+	if v, ok := os.LookupEnv(prefix + "SIZE"); ok {
+		size, err := strconv.Atoi(strings.TrimSpace(v))
+		if err != nil {
+			return Config{}, fmt.Errorf("%sSIZE: %w", prefix, err)
+		}
+		c.Size = size
+	}
+	if v, ok := os.LookupEnv(prefix + "EXPIRY"); ok {
+		expiry, err := parseDuration(v)
+		if err != nil {
+			return Config{}, fmt.Errorf("%sEXPIRY: %w", prefix, err)
+		}
+		c.Expiry = expiry
+	}
+	for name, field := range map[string]*bool{
+		"PREALLOC":      &c.PreAlloc,
+		"NONBLOCKING":   &c.Nonblocking,
+		"DISABLE_CLEAR": &c.DisableClear,
+	} {
+		if v, ok := os.LookupEnv(prefix + name); ok {
+			b, err := strconv.ParseBool(strings.TrimSpace(v))
+			if err != nil {
+				return Config{}, fmt.Errorf("%s%s: %w", prefix, name, err)
+			}
+			*field = b
+		}
+	}
+	return c, c.Validate()
+}
@@ -0,0 +1,171 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/POABOB/grpool"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadJSON(t *testing.T) {
+	c, err := LoadJSON(strings.NewReader(`{"size": 10, "expiry": "2s", "nonblocking": true}`))
+	assert.NoError(t, err)
+	assert.Equal(t, Config{Size: 10, Expiry: Duration(2 * time.Second), Nonblocking: true}, c)
+
+	// 數字視為秒數
+	c, err = LoadJSON(strings.NewReader(`{"size": 1, "expiry": 3}`))
+	assert.NoError(t, err)
+	assert.Equal(t, Duration(3*time.Second), c.Expiry)
+
+	_, err = LoadJSON(strings.NewReader(`{"size": 1, "expiry": "-1s"}`))
+	assert.ErrorIs(t, err, grpool.ErrInvalidPoolExpiry)
+}
+
+func TestLoadYAML(t *testing.T) {
+	c, err := LoadYAML(strings.NewReader("size: 5\nexpiry: 500ms\nprealloc: true\ndisable_clear: true\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, Config{Size: 5, Expiry: Duration(500 * time.Millisecond), PreAlloc: true, DisableClear: true}, c)
+
+	// 空的檔案使用預設值
+	c, err = LoadYAML(strings.NewReader(""))
+	assert.NoError(t, err)
+	assert.Equal(t, Config{}, c)
+
+	p, err := c.NewPool()
+	assert.NoError(t, err)
+	defer p.Release()
+	assert.Equal(t, -1, p.Cap())
+}
+
+func TestLoadEnv(t *testing.T) {
+	t.Setenv("GRPOOL_SIZE", "8")
+	t.Setenv("GRPOOL_EXPIRY", "1m")
+	t.Setenv("GRPOOL_NONBLOCKING", "true")
+
+	c, err := LoadEnv("GRPOOL_", Config{Size: 1, DisableClear: true})
+	assert.NoError(t, err)
+	assert.Equal(t, Config{Size: 8, Expiry: Duration(time.Minute), Nonblocking: true, DisableClear: true}, c)
+
+	t.Setenv("GRPOOL_PREALLOC", "yes")
+	_, err = LoadEnv("GRPOOL_", Config{})
+	assert.Error(t, err)
+}
+
+func TestLoadFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "pool.yml")
+	assert.NoError(t, os.WriteFile(path, []byte("size: 3\n"), 0o644))
+	c, err := LoadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, c.Size)
+
+	path = filepath.Join(dir, "pool.toml")
+	assert.NoError(t, os.WriteFile(path, []byte("size = 3\n"), 0o644))
+	_, err = LoadFile(path)
+	assert.ErrorIs(t, err, ErrUnknownFormat)
+}
+
+func TestWatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pool.json")
+	assert.NoError(t, os.WriteFile(path, []byte(`{"size": 2}`), 0o644))
+	c, err := LoadFile(path)
+	assert.NoError(t, err)
+	p, err := c.NewPool()
+	assert.NoError(t, err)
+	defer p.Release()
+
+	errs := make(chan error, 1)
+	w := Watch(p, path, c, 5*time.Millisecond, func(err error) {
+		// 寫入途中可能讀到不完整的檔案，只保留驗證的錯誤
+		if errors.Is(err, grpool.ErrInvalidPoolExpiry) {
+			select {
+			case errs <- err:
+			default:
+			}
+		}
+	})
+	defer w.Stop()
+
+	// 只改變容量時使用 Tune
+	assert.NoError(t, os.WriteFile(path, []byte(`{"size": 4}`), 0o644))
+	assert.Eventually(t, func() bool { return p.Cap() == 4 }, time.Second, 5*time.Millisecond)
+	assert.EqualValues(t, grpool.OPENED, p.State())
+
+	// 錯誤的設定不會套用
+	assert.NoError(t, os.WriteFile(path, []byte(`{"size": 4, "expiry": "-1s"}`), 0o644))
+	select {
+	case err := <-errs:
+		assert.ErrorIs(t, err, grpool.ErrInvalidPoolExpiry)
+	case <-time.After(time.Second):
+		t.Fatal("invalid config should be reported")
+	}
+	assert.Equal(t, 4, w.Config().Size)
+
+	// 其他設定直接套用，不會重啟 Pool
+	assert.NoError(t, os.WriteFile(path, []byte(`{"size": 1, "nonblocking": true}`), 0o644))
+	assert.Eventually(t, func() bool { return w.Config().Nonblocking }, time.Second, 5*time.Millisecond)
+	assert.Equal(t, 1, p.Cap())
+	assert.False(t, p.IsClosed())
+
+	release := make(chan struct{})
+	assert.NoError(t, p.Schedule(func() { <-release }))
+	assert.ErrorIs(t, p.Schedule(func() {}), grpool.ErrPoolOverload)
+	close(release)
+}
+
+func TestApplyValidatesMergedOptions(t *testing.T) {
+	c := Config{Size: 10}
+	p, err := c.NewPool(grpool.WithMinIdleWorkers(8))
+	assert.NoError(t, err)
+	defer p.Release()
+
+	// MinIdleWorkers 超過新的容量，Pool 維持原本的容量繼續執行
+	err = Apply(p, c, Config{Size: 5, Nonblocking: true})
+	assert.ErrorIs(t, err, grpool.ErrInvalidMinIdleWorkers)
+	assert.EqualValues(t, grpool.OPENED, p.State())
+	assert.Equal(t, 10, p.Cap())
+	assert.False(t, p.Options().Nonblocking)
+}
+
+func TestApplyKeepsJobsAndTimers(t *testing.T) {
+	c := Config{Size: 2}
+	p, err := c.NewPool()
+	assert.NoError(t, err)
+	defer p.Release()
+
+	var ticks int32
+	job, err := p.Every(5*time.Millisecond, func() { atomic.AddInt32(&ticks, 1) })
+	assert.NoError(t, err)
+	defer job.Stop()
+	fired := make(chan struct{})
+	_, err = p.ScheduleAfter(50*time.Millisecond, func() { close(fired) })
+	assert.NoError(t, err)
+
+	// 只改變容量以外的設定時，Pool 不會排空，週期任務與延遲任務都繼續執行
+	next := Config{Size: 3, Expiry: Duration(time.Minute), Nonblocking: true, PreAlloc: true}
+	assert.NoError(t, Apply(p, c, next))
+	assert.EqualValues(t, grpool.OPENED, p.State())
+	assert.Equal(t, 3, p.Cap())
+	opts := p.Options()
+	assert.True(t, opts.Nonblocking)
+	assert.True(t, opts.PreAlloc)
+	assert.Equal(t, time.Minute, opts.ExpiryDuration)
+	assert.Equal(t, time.Minute, p.Expiry())
+
+	assert.NoError(t, Apply(p, next, Config{Size: 3, DisableClear: true}))
+	assert.True(t, p.Options().DisableClear)
+
+	before := atomic.LoadInt32(&ticks)
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(&ticks) > before+2 }, time.Second, time.Millisecond)
+	select {
+	case <-fired:
+	case <-time.After(time.Second):
+		t.Fatal("delayed task should survive Apply")
+	}
+}
@@ -0,0 +1,119 @@
+package config
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/POABOB/grpool"
+)
+
+var ErrUnsupportedChange = errors.New("changing between a limited and an unlimited size requires a new pool")
+
+// 把設定的變更套用到執行中的 Pool: 先以新的容量檢查 Pool 合併後的設定，容量改變時使用 Tune，
+// 其他設定以 Reconfigure 直接套用，不會 Drain 或重啟 Pool，已經提交的任務、延遲任務與週期任務都不受影響，
+// 失敗時 Pool 維持原本的容量與設定
+func Apply(p *grpool.Pool, old, c Config) error {
+	if c.Size != old.Size && (c.Size <= 0 || old.Size <= 0) {
+		return ErrUnsupportedChange
+	}
+	// 沒有放在 Config 的設定 (例如 MinIdleWorkers) 也要符合新的容量
+	options := append([]grpool.Option{grpool.WithOptions(p.Options())}, c.Options()...)
+	if err := grpool.ValidateOptions(c.Size, options...); err != nil {
+		return err
+	}
+
+	p.Tune(c.Size)
+	resized := old
+	resized.Size = c.Size
+	if resized == c {
+		return nil
+	}
+	if err := p.Reconfigure(c.liveOptions()); err != nil {
+		p.Tune(old.Size)
+		return err
+	}
+	return nil
+}
+
+// 定期重新讀取設定檔，內容改變時套用到 Pool
+type Watcher struct {
+	path string
+	pool *grpool.Pool
+
+	// 讀取或套用失敗時呼叫，失敗時維持目前的設定
+	onError func(error)
+
+	lock    sync.Mutex
+	current Config
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// 每隔 interval 檢查 path，current 為 Pool 目前使用的設定，onError 可以為 nil
+func Watch(p *grpool.Pool, path string, current Config, interval time.Duration, onError func(error)) *Watcher {
+	w := &Watcher{
+		path:    path,
+		pool:    p,
+		onError: onError,
+		current: current,
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	go w.run(interval)
+	return w
+}
+
+func (w *Watcher) run(interval time.Duration) {
+	defer close(w.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+			if err := w.check(); err != nil && w.onError != nil {
+				w.onError(err)
+			}
+		}
+	}
+}
+
+// 讀取設定檔，與目前的設定不同時套用
+func (w *Watcher) check() error {
+	c, err := LoadFile(w.path)
+	if err != nil {
+		return err
+	}
+
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if c == w.current {
+		return nil
+	}
+	if err := Apply(w.pool, w.current, c); err != nil {
+		return err
+	}
+	w.current = c
+	return nil
+}
+
+// 獲取目前套用的設定
+func (w *Watcher) Config() Config {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	return w.current
+}
+
+// 停止檢查設定檔，等待正在套用的設定完成
+func (w *Watcher) Stop() {
+	select {
+	case <-w.stop:
+	default:
+		close(w.stop)
+	}
+	<-w.done
+}
@@ -0,0 +1,175 @@
+package grpool
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Cron 表達式的各個欄位範圍
+type cronBounds struct {
+	min, max int
+	name     string
+}
+
+var (
+	minuteBounds = cronBounds{0, 59, "minute"}
+	hourBounds   = cronBounds{0, 23, "hour"}
+	domBounds    = cronBounds{1, 31, "day of month"}
+	monthBounds  = cronBounds{1, 12, "month"}
+	dowBounds    = cronBounds{0, 7, "day of week"}
+)
+
+// 預先定義的 Cron 表達式
+var cronDescriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// 解析後的 Cron 表達式，每個欄位以 bit 表示符合的值
+type cronSchedule struct {
+	minute, hour, dom, month, dow uint64
+
+	// 日期與星期都有限制時，任一符合即可
+	domStar, dowStar bool
+}
+
+// 解析標準的 5 個欄位 Cron 表達式: 分 時 日 月 星期
+func parseCron(spec string) (*cronSchedule, error) {
+	spec = strings.TrimSpace(spec)
+	if d, ok := cronDescriptors[spec]; ok {
+		spec = d
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("%w: expected 5 fields, found %d: %q", ErrInvalidCronSpec, len(fields), spec)
+	}
+
+	s := &cronSchedule{
+		domStar: fields[2] == "*" || fields[2] == "?",
+		dowStar: fields[4] == "*" || fields[4] == "?",
+	}
+	var err error
+	if s.minute, err = parseCronField(fields[0], minuteBounds); err != nil {
+		return nil, err
+	}
+	if s.hour, err = parseCronField(fields[1], hourBounds); err != nil {
+		return nil, err
+	}
+	if s.dom, err = parseCronField(fields[2], domBounds); err != nil {
+		return nil, err
+	}
+	if s.month, err = parseCronField(fields[3], monthBounds); err != nil {
+		return nil, err
+	}
+	if s.dow, err = parseCronField(fields[4], dowBounds); err != nil {
+		return nil, err
+	}
+	// 7 和 0 都代表星期日
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1
+	}
+	return s, nil
+}
+
+// 解析單一欄位，支援 *、?、a-b、*/n、a-b/n 以及以逗號分隔的清單
+func parseCronField(field string, b cronBounds) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rangeAndStep := strings.SplitN(part, "/", 2)
+		start, end := b.min, b.max
+
+		switch r := rangeAndStep[0]; {
+		case r == "*" || r == "?":
+		case strings.Contains(r, "-"):
+			bounds := strings.SplitN(r, "-", 2)
+			var err error
+			if start, err = parseCronValue(bounds[0], b); err != nil {
+				return 0, err
+			}
+			if end, err = parseCronValue(bounds[1], b); err != nil {
+				return 0, err
+			}
+			if start > end {
+				return 0, fmt.Errorf("%w: %s range %q is reversed", ErrInvalidCronSpec, b.name, r)
+			}
+		default:
+			v, err := parseCronValue(r, b)
+			if err != nil {
+				return 0, err
+			}
+			start, end = v, v
+			// a/n 代表從 a 開始到最大值
+			if len(rangeAndStep) == 2 {
+				end = b.max
+			}
+		}
+
+		step := 1
+		if len(rangeAndStep) == 2 {
+			var err error
+			if step, err = strconv.Atoi(rangeAndStep[1]); err != nil || step <= 0 {
+				return 0, fmt.Errorf("%w: invalid %s step %q", ErrInvalidCronSpec, b.name, rangeAndStep[1])
+			}
+		}
+
+		for v := start; v <= end; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+func parseCronValue(s string, b cronBounds) (int, error) {
+	v, err := strconv.Atoi(s)
+	if err != nil || v < b.min || v > b.max {
+		return 0, fmt.Errorf("%w: %s value %q out of range [%d, %d]", ErrInvalidCronSpec, b.name, s, b.min, b.max)
+	}
+	return v, nil
+}
+
+// 找出 t 之後第一個符合的時間，找不到就返回零值
+func (s *cronSchedule) next(t time.Time) time.Time {
+	loc := t.Location()
+	t = t.Truncate(time.Minute).Add(time.Minute)
+
+	// 最多往後找 5 年，避免像 2 月 30 日這種永遠不會符合的表達式無限循環
+	limit := t.Year() + 5
+	for t.Year() <= limit {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if !s.matchDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// 日期與星期只要有一邊是 * 就必須兩者都符合，否則任一符合即可
+func (s *cronSchedule) matchDay(t time.Time) bool {
+	domMatch := s.dom&(1<<uint(t.Day())) != 0
+	dowMatch := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domStar || s.dowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
@@ -0,0 +1,47 @@
+package grpool
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCron(t *testing.T) {
+	for _, spec := range []string{"* * * * *", "*/5 0-6 1,15 * 1-5", "0 12 * * 7", "@daily", "5/10 * * * *"} {
+		_, err := parseCron(spec)
+		assert.NoErrorf(t, err, "spec: %s", spec)
+	}
+
+	for _, spec := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "5-1 * * * *", "*/0 * * * *", "a * * * *"} {
+		_, err := parseCron(spec)
+		assert.ErrorIsf(t, err, ErrInvalidCronSpec, "spec: %s", spec)
+	}
+}
+
+func TestCronNext(t *testing.T) {
+	from := time.Date(2024, time.January, 31, 23, 59, 30, 0, time.UTC) // 星期三
+	tests := []struct {
+		spec string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC)},
+		{"30 9 * * *", time.Date(2024, time.February, 1, 9, 30, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 0", time.Date(2024, time.February, 4, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2024, time.February, 4, 0, 0, 0, 0, time.UTC)},
+		// 日期與星期都有限制時，任一符合即可
+		{"0 0 15 * 5", time.Date(2024, time.February, 2, 0, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		s, err := parseCron(tt.spec)
+		assert.NoError(t, err)
+		assert.Equalf(t, tt.want, s.next(from), "spec: %s", tt.spec)
+	}
+
+	s, _ := parseCron("0 0 30 2 *")
+	assert.True(t, s.next(from).IsZero(), "Feb 30 should never match")
+}
@@ -7,7 +7,7 @@ import (
 )
 
 const (
-	// 預設 Pool 最大容量
+	// 需要固定上限時建議的最大容量，NewDefaultPool 沒有上限，不會事先申請這麼多空間
 	DefaultPoolSize = 10_000_000
 
 	// 預設每 1 秒清理一次 Pool
@@ -21,16 +21,37 @@ const (
 
 	// Pool 關閉
 	CLOSED = 1
+
+	// Pool 排空中: 不接受新的任務，已經提交的任務執行完後關閉
+	DRAINING = 2
 )
 
 // 定義各種錯誤
 var (
-	ErrLackPoolFunc        = errors.New("must provide func for pool")
-	ErrInvalidPoolExpiry   = errors.New("invalid pool expiry")
-	ErrPoolClosed          = errors.New("pool has been closed")
-	ErrPoolOverload        = errors.New("too many goroutines blocked or Nonblocking is set")
-	ErrInvalidPreAllocSize = errors.New("can not set up a negative capacity under PreAlloc mode")
-	ErrTimeout             = errors.New("operation timed out")
+	ErrLackPoolFunc           = errors.New("must provide func for pool")
+	ErrInvalidPoolExpiry      = errors.New("invalid pool expiry")
+	ErrPoolClosed             = errors.New("pool has been closed")
+	ErrPoolOverload           = errors.New("too many goroutines blocked or Nonblocking is set")
+	ErrInvalidPreAllocSize    = errors.New("can not set up a negative capacity under PreAlloc mode")
+	ErrTimeout                = errors.New("operation timed out")
+	ErrInvalidJobInterval     = errors.New("invalid job interval")
+	ErrInvalidCronSpec        = errors.New("invalid cron spec")
+	ErrTaskPanicked           = errors.New("task panicked")
+	ErrInvalidTaskQueueSize   = errors.New("invalid task queue size")
+	ErrInvalidMinIdleWorkers  = errors.New("invalid min idle workers")
+	ErrInvalidCleanInterval   = errors.New("invalid clean interval")
+	ErrInvalidAutoscale       = errors.New("invalid autoscale range")
+	ErrMemoryLimitExceeded    = errors.New("memory usage exceeds the limit")
+	ErrInvalidWeight          = errors.New("invalid task weight")
+	ErrTagOverload            = errors.New("too many running tasks for the tag")
+	ErrInvalidTagLimit        = errors.New("invalid tag limit")
+	ErrInvalidTenantWeight    = errors.New("invalid tenant weight")
+	ErrPoolDraining           = errors.New("pool is draining")
+	ErrPoolNotClosed          = errors.New("pool is not closed")
+	ErrInvalidMaxCleanPerTick = errors.New("invalid max clean per tick")
+	ErrInvalidNestedPolicy    = errors.New("invalid nested policy")
+	ErrInvalidMemoryLimit     = errors.New("invalid memory limit")
+	ErrInvalidChildOption     = errors.New("child pools only support the Nonblocking and PanicHandler options")
 
 	// workerChanCap determines whether the channel of a worker should be a buffered channel
 	// to get the best performance. Inspired by fasthttp at
@@ -49,8 +70,8 @@ var (
 	}()
 )
 
-// 初始化一個預設的 Pool
+// 初始化一個預設的 Pool，容量沒有上限，閒置 Worker 的佇列依照數量增減空間
 func NewDefaultPool() (defaultPool *Pool) {
-	defaultPool, _ = NewPool(DefaultPoolSize)
+	defaultPool, _ = NewPool(-1)
 	return
 }
@@ -46,3 +46,76 @@ func BenchmarkGrpoolThroughput(b *testing.B) {
 	}
 	wg.Wait()
 }
+
+// 解析 goroutine id 的成本
+func BenchmarkGoid(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		_ = goid()
+	}
+}
+
+// 在 Pool 外面提交任務時，設定 NestedPolicy 不應該增加成本
+func BenchmarkScheduleNestedPolicy(b *testing.B) {
+	for _, bc := range []struct {
+		name string
+		opts []Option
+	}{
+		{"Default", nil},
+		{"NestedCallerRuns", []Option{WithNestedPolicy(NestedCallerRuns)}},
+		{"WorkStealing", []Option{WithWorkStealing(true)}},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			p, _ := NewPool(poolSize, append(bc.opts, WithExpiryDuration(expiredTime))...)
+			defer p.Release()
+
+			var wg sync.WaitGroup
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				wg.Add(1)
+				_ = p.Schedule(wg.Done)
+			}
+			wg.Wait()
+		})
+	}
+}
+
+// 沒有上限的 Pool 保留 1000 個閒置 Worker 時，Worker 佇列佔用的空間 (B/op)
+func BenchmarkUnlimitedWorkerQueueFootprint(b *testing.B) {
+	const idle = 1000
+	queues := []struct {
+		name     string
+		newQueue func() workerQueue
+	}{
+		{"CircularQueue", func() workerQueue { return newWorkerCircularQueue(DefaultPoolSize, false) }},
+		{"CircularQueuePreAlloc", func() workerQueue { return newWorkerCircularQueue(DefaultPoolSize, true) }},
+		{"GrowableQueue", func() workerQueue { return newWorkerGrowableQueue() }},
+	}
+	workers := make([]worker, idle)
+	for i := range workers {
+		workers[i] = &Worker{lastUpdatedTime: time.Now()}
+	}
+
+	for _, q := range queues {
+		b.Run(q.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				wq := q.newQueue()
+				for _, w := range workers {
+					_ = wq.insert(w)
+				}
+				for wq.detach() != nil {
+				}
+			}
+		})
+	}
+}
+
+// 建立沒有上限的 Pool 並保留 1000 個閒置 Worker 佔用的空間 (B/op)
+func BenchmarkUnlimitedPoolFootprint(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		p, _ := NewPool(-1, WithDisableClear(true))
+		_ = p.Warmup(1000)
+		p.Release()
+	}
+}
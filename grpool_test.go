@@ -1,11 +1,14 @@
 package grpool
 
 import (
+	"context"
 	"fmt"
 	"runtime"
 	"sync"
 	"testing"
 	"time"
+
+	"github.com/stretchr/testify/assert"
 )
 
 const (
@@ -219,3 +222,336 @@ func TestGrPoolWithOptions(t *testing.T) {
 	curMem = mem.TotalAlloc/MiB - curMem
 	t.Logf("memory usage:%d MB", curMem)
 }
+
+func TestValidateOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		size    int
+		options []Option
+		err     error
+	}{
+		{"negative expiry", 10, []Option{WithExpiryDuration(-1)}, ErrInvalidPoolExpiry},
+		{"negative expiry without clear", 10, []Option{WithExpiryDuration(-1), WithDisableClear(true)}, ErrInvalidPoolExpiry},
+		{"prealloc unlimited", 0, []Option{WithPreAlloc(true)}, ErrInvalidPreAllocSize},
+		{"prealloc negative", -1, []Option{WithPreAlloc(true)}, ErrInvalidPreAllocSize},
+		{"negative max clean", 10, []Option{WithMaxCleanPerTick(-1)}, ErrInvalidMaxCleanPerTick},
+		{"min idle over size", 10, []Option{WithMinIdleWorkers(11)}, ErrInvalidMinIdleWorkers},
+		{"min idle over autoscale max", 10, []Option{WithMinIdleWorkers(21), WithAutoscale(Autoscale{Min: 1, Max: 20})}, ErrInvalidMinIdleWorkers},
+		{"unknown nested policy", 10, []Option{WithNestedPolicy(NestedPolicy(5))}, ErrInvalidNestedPolicy},
+		{"memory ratio", 10, []Option{WithMemoryLimit(MemoryLimit{Ratio: 1.5})}, ErrInvalidMemoryLimit},
+		{"memory interval", 10, []Option{WithMemoryLimit(MemoryLimit{Interval: -1})}, ErrInvalidMemoryLimit},
+		{"prealloc autoscale", 0, []Option{WithPreAlloc(true), WithAutoscale(Autoscale{Min: 1, Max: 20})}, nil},
+		{"min idle unlimited", 0, []Option{WithMinIdleWorkers(100)}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorIs(t, ValidateOptions(tt.size, tt.options...), tt.err)
+
+			p, err := NewPool(tt.size, tt.options...)
+			assert.ErrorIs(t, err, tt.err)
+			if err == nil {
+				p.Release()
+			}
+		})
+	}
+}
+
+func TestPoolOptions(t *testing.T) {
+	p, _ := NewPool(size, WithNonblocking(true), WithTagLimit("a", 1))
+	defer p.Release()
+
+	opts := p.Options()
+	assert.True(t, opts.Nonblocking)
+	// 補上預設值
+	assert.Equal(t, DefaultCleanIntervalTime, opts.ExpiryDuration)
+	assert.Equal(t, map[string]int{"a": 1}, opts.TagLimits)
+
+	// 修改複本不會影響 Pool
+	opts.TagLimits["a"] = 5
+	opts.Nonblocking = false
+	assert.Equal(t, 1, p.Options().TagLimits["a"])
+	assert.True(t, p.Options().Nonblocking)
+
+	p.Release()
+	assert.NoError(t, p.RebootWithOptions(WithExpiryDuration(time.Minute)))
+	assert.Equal(t, time.Minute, p.Options().ExpiryDuration)
+}
+
+func TestPoolOptionsDeepCopy(t *testing.T) {
+	p, err := NewPool(2,
+		WithAutoscale(Autoscale{Min: 1, Max: 4}),
+		WithAdaptiveExpiry(AdaptiveExpiry{MinExpiry: time.Second, MaxExpiry: time.Minute}),
+		WithMemoryLimit(MemoryLimit{SoftLimit: 1024 * MiB}),
+	)
+	assert.NoError(t, err)
+	defer p.Release()
+
+	// 修改複本指向的設定不會影響 Pool
+	opts := p.Options()
+	opts.Autoscale.Max = 100
+	opts.AdaptiveExpiry.MaxExpiry = time.Hour
+	opts.MemoryLimit.SoftLimit = 1
+	assert.Equal(t, 4, p.Options().Autoscale.Max)
+	assert.Equal(t, time.Minute, p.Options().AdaptiveExpiry.MaxExpiry)
+	assert.Equal(t, 1024*MiB, p.Options().MemoryLimit.SoftLimit)
+}
+
+func TestGrPoolWithMinIdleWorkers(t *testing.T) {
+	p, _ := NewPool(size, WithMinIdleWorkers(10), WithExpiryDuration(100*time.Millisecond))
+	defer p.Release()
+
+	assert.NoError(t, p.Warmup(50))
+	assert.EqualValues(t, 50, p.Running())
+
+	time.Sleep(350 * time.Millisecond)
+	assert.EqualValues(t, 10, p.Running(), "should keep min idle workers")
+
+	// 超過容量的部分會被忽略
+	p2, _ := NewPool(5)
+	defer p2.Release()
+	assert.NoError(t, p2.Warmup(10))
+	assert.EqualValues(t, 5, p2.Running())
+	assert.NoError(t, p2.Schedule(demoFunc))
+	assert.EqualValues(t, 5, p2.Running(), "warmed up workers should be reused")
+
+	p2.Release()
+	assert.ErrorIs(t, p2.Warmup(1), ErrPoolClosed)
+
+	_, err := NewPool(size, WithMinIdleWorkers(-1))
+	assert.ErrorIs(t, err, ErrInvalidMinIdleWorkers)
+}
+
+func TestGrPoolWithCleanInterval(t *testing.T) {
+	p, _ := NewPool(size, WithCleanInterval(10*time.Millisecond), WithExpiryDuration(100*time.Millisecond), WithMaxCleanPerTick(10))
+	defer p.Release()
+
+	_ = p.Warmup(50)
+	time.Sleep(50 * time.Millisecond)
+	assert.EqualValues(t, 50, p.Running(), "workers should not expire before ExpiryDuration")
+
+	// 過期後每次最多清理 10 個
+	time.Sleep(80 * time.Millisecond)
+	running := p.Running()
+	assert.Less(t, running, 50)
+	assert.Greater(t, running, 0)
+
+	time.Sleep(100 * time.Millisecond)
+	assert.EqualValues(t, 0, p.Running())
+
+	_, err := NewPool(size, WithCleanInterval(-1))
+	assert.ErrorIs(t, err, ErrInvalidCleanInterval)
+}
+
+func TestClearStaleWorkersReturnsWhenClosed(t *testing.T) {
+	p, _ := NewPool(size, WithExpiryDuration(10*time.Millisecond))
+	p.Release()
+
+	done := make(chan struct{})
+	go func() {
+		p.ClearStaleWorkers(context.Background())
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("ClearStaleWorkers should return when the pool is closed")
+	}
+}
+
+func TestPoolDrain(t *testing.T) {
+	p, _ := NewPool(2, WithTaskQueue(2))
+	release := occupyPool(p, 2)
+
+	// 排隊中與阻塞等待的任務
+	var ran sync.WaitGroup
+	ran.Add(3)
+	for i := 0; i < 2; i++ {
+		assert.NoError(t, p.Schedule(ran.Done))
+	}
+	blocked := make(chan error, 1)
+	go func() {
+		blocked <- p.Schedule(ran.Done)
+	}()
+	for p.Waiting() != 1 {
+		time.Sleep(time.Millisecond)
+	}
+
+	p.Drain()
+	assert.EqualValues(t, DRAINING, p.State())
+	assert.ErrorIs(t, p.Schedule(demoFunc), ErrPoolDraining)
+	_, err := p.ScheduleAfter(time.Millisecond, demoFunc)
+	assert.ErrorIs(t, err, ErrPoolDraining)
+
+	select {
+	case <-p.Done():
+		t.Fatal("pool should not stop before the submitted tasks finish")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	release()
+	ran.Wait()
+	assert.NoError(t, <-blocked)
+
+	select {
+	case <-p.Done():
+	case <-time.After(time.Second):
+		t.Fatal("pool should stop after draining")
+	}
+	assert.EqualValues(t, CLOSED, p.State())
+	assert.EqualValues(t, 0, p.Running())
+}
+
+func TestPoolDone(t *testing.T) {
+	p, _ := NewPool(10)
+	release := occupyPool(p, 2)
+
+	p.Release()
+	assert.EqualValues(t, CLOSED, p.State())
+	select {
+	case <-p.Done():
+		t.Fatal("pool should not stop before the running tasks finish")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	release()
+	select {
+	case <-p.Done():
+	case <-time.After(time.Second):
+		t.Fatal("pool should stop after the running tasks finish")
+	}
+
+	// Reboot 之後換成新的 channel
+	p.Reboot()
+	defer p.Release()
+	assert.EqualValues(t, OPENED, p.State())
+	select {
+	case <-p.Done():
+		t.Fatal("rebooted pool should not be done")
+	default:
+	}
+}
+
+func TestPoolRebootWhileClearing(t *testing.T) {
+	p, _ := NewPool(10, WithCleanInterval(time.Millisecond), WithExpiryDuration(time.Millisecond))
+	defer p.Release()
+
+	// Release 之後立刻 Reboot，上一次的清理 goroutine 可能還沒退出
+	for i := 0; i < 100; i++ {
+		_ = p.Schedule(demoFunc)
+		p.Release()
+		p.Reboot()
+	}
+	assert.EqualValues(t, OPENED, p.State())
+	assert.NoError(t, p.Schedule(demoFunc))
+}
+
+func TestPoolRebootWithOptions(t *testing.T) {
+	p, _ := NewPool(2, WithTagLimit("a", 1))
+	assert.ErrorIs(t, p.RebootWithOptions(WithNonblocking(true)), ErrPoolNotClosed)
+
+	old := p.currentOptions()
+	p.Release()
+
+	// 檢查規則與 NewPool 相同，失敗時維持關閉
+	assert.ErrorIs(t, p.RebootWithOptions(WithExpiryDuration(-1)), ErrInvalidPoolExpiry)
+	assert.True(t, p.IsClosed())
+
+	panics := make(chan interface{}, 1)
+	assert.NoError(t, p.RebootWithOptions(
+		WithNonblocking(true),
+		WithPreAlloc(true),
+		WithExpiryDuration(time.Minute),
+		WithPanicHandler(func(v interface{}) { panics <- v }),
+		WithTagLimit("b", 2),
+	))
+	defer p.Release()
+
+	assert.False(t, p.IsClosed())
+	assert.Equal(t, 2, p.Cap())
+	assert.Equal(t, time.Minute, p.currentOptions().ExpiryDuration)
+	assert.Equal(t, map[string]int{"a": 1, "b": 2}, p.currentOptions().TagLimits)
+	assert.Equal(t, map[string]int{"a": 1}, old.TagLimits, "previous options should not be modified")
+	// 重新建立提前申請空間的佇列
+	assert.True(t, p.workers.(*circularQueue).isPreAlloc)
+
+	assert.NoError(t, p.Schedule(func() { panic("boom") }))
+	select {
+	case v := <-panics:
+		assert.Equal(t, "boom", v)
+	case <-time.After(time.Second):
+		t.Fatal("new panic handler should be used")
+	}
+
+	release := occupyPool(p, 2)
+	defer release()
+	assert.ErrorIs(t, p.Schedule(demoFunc), ErrPoolOverload)
+}
+
+func TestPoolRebootWithOptionsConcurrentReads(t *testing.T) {
+	p, _ := NewPool(4)
+	defer p.Release()
+
+	// 重啟期間讀取設定與狀態，需要搭配 -race 檢查
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			_ = p.Options()
+			_ = p.Limit()
+			_ = p.Expiry()
+			_ = p.Queued()
+			ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+			_ = p.WaitIdle(ctx)
+			cancel()
+		}
+	}()
+
+	for i := 0; i < 50; i++ {
+		p.Release()
+		assert.NoError(t, p.RebootWithOptions(
+			WithConcurrencyLimiter(NewVegasLimiter(VegasOptions{})),
+			WithAdaptiveExpiry(AdaptiveExpiry{MinExpiry: time.Second, MaxExpiry: time.Minute}),
+			WithTaskQueue(i%3),
+		))
+	}
+	close(stop)
+	wg.Wait()
+	assert.EqualValues(t, OPENED, p.State())
+}
+
+func TestPoolReconfigure(t *testing.T) {
+	p, _ := NewPool(2, WithTagLimit("a", 1))
+	defer p.Release()
+
+	assert.ErrorIs(t, p.Reconfigure(LiveOptions{ExpiryDuration: -1}), ErrInvalidPoolExpiry)
+	assert.Equal(t, DefaultCleanIntervalTime, p.currentOptions().ExpiryDuration)
+
+	// 執行中的任務與閒置的 Worker 都保留下來
+	release := occupyPool(p, 1)
+	assert.NoError(t, p.Reconfigure(LiveOptions{ExpiryDuration: time.Minute, Nonblocking: true, PreAlloc: true}))
+	assert.EqualValues(t, OPENED, p.State())
+	assert.Equal(t, 1, p.Running())
+	assert.Equal(t, time.Minute, p.Expiry())
+	assert.True(t, p.workers.(*circularQueue).isPreAlloc)
+	// 其他設定維持不變
+	assert.Equal(t, map[string]int{"a": 1}, p.currentOptions().TagLimits)
+
+	block := occupyPool(p, 1)
+	assert.ErrorIs(t, p.Schedule(demoFunc), ErrPoolOverload)
+	block()
+	release()
+
+	assert.NoError(t, p.Reconfigure(LiveOptions{DisableClear: true}))
+	assert.True(t, p.currentOptions().DisableClear)
+	assert.NoError(t, p.Schedule(demoFunc))
+
+	p.Release()
+	assert.ErrorIs(t, p.Reconfigure(LiveOptions{}), ErrPoolClosed)
+}
@@ -0,0 +1,73 @@
+package grpool
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// 等待 Pool 閒置的呼叫
+type idleNotifier struct {
+	lock sync.Mutex
+
+	// Pool 閒置時關閉，關閉後換成新的 channel
+	ch chan struct{}
+
+	// 等待中的呼叫數量，沒有人等待時任務完成不需要加鎖
+	waiters int32
+}
+
+// 等待所有已經提交的任務完成: 沒有執行中、排隊中或阻塞等待 Worker 的任務，閒置的 Worker 與尚未到期的延遲任務不影響，
+// 不會關閉 Pool，ctx 被取消時返回 ctx.Err()
+func (p *Pool) WaitIdle(ctx context.Context) error {
+	n := &p.idle
+	atomic.AddInt32(&n.waiters, 1)
+	defer atomic.AddInt32(&n.waiters, -1)
+
+	for {
+		n.lock.Lock()
+		if p.isIdle() {
+			n.lock.Unlock()
+			return nil
+		}
+		if n.ch == nil {
+			n.ch = make(chan struct{})
+		}
+		ch := n.ch
+		n.lock.Unlock()
+
+		select {
+		case <-ch:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// 是否沒有任何已經提交但還沒完成的任務，不能在持有 p.lock 時呼叫
+func (p *Pool) isIdle() bool {
+	// 從 deque 取出任務時先計入 in-flight 再扣掉 pending，所以要先讀 pending
+	if atomic.LoadInt32(&p.stealing.pending) != 0 || atomic.LoadInt32(&p.inflight) != 0 || p.Waiting() != 0 {
+		return false
+	}
+
+	// 從任務裡面提交而排隊的加權與 tenant 任務也還沒完成
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.tasks.len() == 0 && p.tenants.waiting == 0 && len(p.weighted.waiters) == 0
+}
+
+// 任務完成或 Pool 關閉時呼叫，Pool 閒置就喚醒 WaitIdle，不能在持有 p.lock 時呼叫
+func (p *Pool) notifyIdle() {
+	n := &p.idle
+	if atomic.LoadInt32(&n.waiters) == 0 {
+		return
+	}
+
+	n.lock.Lock()
+	if n.ch != nil && p.isIdle() {
+		close(n.ch)
+		n.ch = nil
+	}
+	n.lock.Unlock()
+}
@@ -0,0 +1,91 @@
+package grpool
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWaitIdle(t *testing.T) {
+	p, _ := NewPool(5, WithTaskQueue(5))
+	defer p.Release()
+
+	// 沒有任務時直接返回
+	assert.NoError(t, p.WaitIdle(context.Background()))
+
+	var count int32
+	submitted := make(chan struct{})
+	go func() {
+		// 包含排隊中與阻塞等待 Worker 的任務
+		for i := 0; i < 30; i++ {
+			_ = p.Schedule(func() {
+				time.Sleep(5 * time.Millisecond)
+				atomic.AddInt32(&count, 1)
+			})
+		}
+		close(submitted)
+	}()
+	<-submitted
+
+	assert.NoError(t, p.WaitIdle(context.Background()))
+	assert.EqualValues(t, 30, atomic.LoadInt32(&count))
+	assert.False(t, p.IsClosed())
+
+	// 閒置之後還可以繼續使用
+	assert.NoError(t, p.Schedule(demoFunc))
+	assert.NoError(t, p.WaitIdle(context.Background()))
+}
+
+func TestWaitIdleCanceled(t *testing.T) {
+	p, _ := NewPool(2)
+	defer p.Release()
+	release := occupyPool(p, 2)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, p.WaitIdle(ctx), context.DeadlineExceeded)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- p.WaitIdle(context.Background())
+	}()
+	release()
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("WaitIdle should return after the tasks finish")
+	}
+}
+
+func TestWaitIdleAfterReleaseWakesWaiters(t *testing.T) {
+	p, _ := NewPool(2)
+	p.Pause()
+
+	blocked := make(chan error, 1)
+	go func() {
+		blocked <- p.Schedule(demoFunc)
+	}()
+	assert.Eventually(t, func() bool { return p.Waiting() == 1 }, time.Second, time.Millisecond)
+
+	idle := make(chan error, 1)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		idle <- p.WaitIdle(ctx)
+	}()
+
+	// 被 Release 喚醒的呼叫結束等待後，WaitIdle 也要返回
+	time.Sleep(10 * time.Millisecond)
+	p.Release()
+	assert.ErrorIs(t, <-blocked, ErrPoolOverload)
+	select {
+	case err := <-idle:
+		assert.NoError(t, err)
+	case <-time.After(500 * time.Millisecond):
+		t.Fatal("WaitIdle should return after the blocked caller is woken up")
+	}
+}
@@ -0,0 +1,253 @@
+package grpool
+
+import (
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// 週期任務上一次還在執行時，下一次觸發的處理方式
+type OverlapPolicy int
+
+const (
+	// 上一次還在執行就跳過這次
+	OverlapSkip OverlapPolicy = iota
+
+	// 等上一次執行完成後再執行，觸發多次也會依序執行
+	OverlapQueue
+
+	// 允許同時執行
+	OverlapAllow
+)
+
+// 週期任務的參數設定
+type JobOption func(opts *JobOptions)
+
+// 週期任務的設定
+type JobOptions struct {
+	// 重疊執行策略，預設為 OverlapSkip
+	Overlap OverlapPolicy
+
+	// 每次觸發時間會隨機延後 [0, Jitter)，避免大量任務同時觸發
+	Jitter time.Duration
+}
+
+func loadJobOptions(options ...JobOption) *JobOptions {
+	opts := new(JobOptions)
+	for _, option := range options {
+		option(opts)
+	}
+	return opts
+}
+
+// 設定重疊執行策略
+func WithOverlapPolicy(policy OverlapPolicy) JobOption {
+	return func(opts *JobOptions) {
+		opts.Overlap = policy
+	}
+}
+
+// 設定觸發時間的隨機延遲
+func WithJitter(jitter time.Duration) JobOption {
+	return func(opts *JobOptions) {
+		opts.Jitter = jitter
+	}
+}
+
+// 在 Pool 上週期執行的任務，Pool 被 Release 後會自動停止
+type Job struct {
+	pool *Pool
+	task func()
+	opts *JobOptions
+
+	// 計算下一次觸發時間
+	next func(time.Time) time.Time
+
+	lock sync.Mutex
+
+	// 下一次觸發的延遲任務
+	timer *Timer
+
+	// 上一次預計觸發的時間，用來避免時間漂移
+	last time.Time
+
+	// 建立時 Pool 的 epoch，Pool 被 Release 之後就不再觸發
+	epoch uint32
+
+	// 正在執行的數量
+	running int
+
+	// OverlapQueue 時等待執行的數量
+	pending int
+
+	// 已經交給 Pool 但還在等待 Worker 的觸發
+	waiting bool
+
+	stopped bool
+}
+
+// 每隔 interval 將任務交給 Pool 執行
+func (p *Pool) Every(interval time.Duration, task func(), options ...JobOption) (*Job, error) {
+	if interval <= 0 {
+		return nil, ErrInvalidJobInterval
+	}
+	return p.startJob(func(t time.Time) time.Time {
+		return t.Add(interval)
+	}, task, options...)
+}
+
+// 依照 Cron 表達式將任務交給 Pool 執行，支援標準的 5 個欄位以及 @hourly、@daily 等描述
+func (p *Pool) Cron(spec string, task func(), options ...JobOption) (*Job, error) {
+	schedule, err := parseCron(spec)
+	if err != nil {
+		return nil, err
+	}
+	return p.startJob(schedule.next, task, options...)
+}
+
+func (p *Pool) startJob(next func(time.Time) time.Time, task func(), options ...JobOption) (*Job, error) {
+	if task == nil {
+		return nil, ErrLackPoolFunc
+	}
+	if err := p.checkAccepting(); err != nil {
+		return nil, err
+	}
+
+	j := &Job{
+		pool:  p,
+		task:  task,
+		opts:  loadJobOptions(options...),
+		next:  next,
+		last:  time.Now(),
+		epoch: atomic.LoadUint32(&p.epoch),
+	}
+
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	if err := j.scheduleNext(); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+// 停止週期任務，已經在執行的任務不受影響
+func (j *Job) Stop() {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	j.stopped = true
+	j.pending = 0
+	if j.timer != nil {
+		j.timer.Stop()
+		j.timer = nil
+	}
+}
+
+// 預約下一次觸發，呼叫時必須持有 j.lock
+func (j *Job) scheduleNext() error {
+	now := time.Now()
+	at := j.next(j.last)
+	// 錯過的觸發直接略過
+	if at.Before(now) {
+		at = j.next(now)
+	}
+	if at.IsZero() {
+		j.stopped = true
+		return ErrInvalidCronSpec
+	}
+	j.last = at
+
+	if jitter := j.opts.Jitter; jitter > 0 {
+		at = at.Add(time.Duration(rand.Int63n(int64(jitter))))
+	}
+
+	timer, err := j.pool.scheduleAt(at, j.fire, j.due, j.epoch)
+	if err != nil {
+		j.stopped = true
+		j.timer = nil
+		return err
+	}
+	j.timer = timer
+	return nil
+}
+
+// 到期時在派發的 goroutine 上預約下一次觸發，不需要等到 fire 在 Worker 上執行，
+// Pool 滿載時上一次的觸發還在等待 Worker，這次的觸發與上一次還在執行時相同，依照 OverlapPolicy 跳過或累計，
+// OverlapAllow 也會跳過，避免佇列中堆積觸發
+func (j *Job) due() bool {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	if j.stopped || atomic.LoadUint32(&j.pool.epoch) != j.epoch {
+		j.stopped = true
+		return false
+	}
+	// Pool 已經被關閉時會自動停止
+	_ = j.scheduleNext()
+
+	if j.waiting {
+		if j.opts.Overlap == OverlapQueue {
+			j.pending++
+		}
+		return false
+	}
+	j.waiting = true
+	return true
+}
+
+// 觸發時已經在 Worker 上執行
+func (j *Job) fire() {
+	j.lock.Lock()
+	j.waiting = false
+	// Release 之前就已經派發的觸發也不執行
+	if j.stopped || atomic.LoadUint32(&j.pool.epoch) != j.epoch {
+		j.stopped = true
+		j.lock.Unlock()
+		return
+	}
+
+	if j.running > 0 {
+		switch j.opts.Overlap {
+		case OverlapSkip:
+			j.lock.Unlock()
+			return
+		case OverlapQueue:
+			j.pending++
+			j.lock.Unlock()
+			return
+		}
+	}
+	j.running++
+	j.lock.Unlock()
+
+	j.run()
+}
+
+// 執行任務，OverlapQueue 時會接著執行等待中的任務
+func (j *Job) run() {
+	finished := false
+	defer func() {
+		// 任務 panic 時也要釋放
+		if !finished {
+			j.lock.Lock()
+			j.running--
+			j.lock.Unlock()
+		}
+	}()
+
+	for {
+		j.task()
+
+		// 檢查 pending 與釋放 running 必須在同一個臨界區，避免漏掉觸發
+		j.lock.Lock()
+		if j.pending == 0 {
+			j.running--
+			finished = true
+			j.lock.Unlock()
+			return
+		}
+		j.pending--
+		j.lock.Unlock()
+	}
+}
@@ -0,0 +1,125 @@
+package grpool
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvery(t *testing.T) {
+	p, _ := NewPool(10)
+	defer p.Release()
+
+	var count int32
+	job, err := p.Every(20*time.Millisecond, func() {
+		atomic.AddInt32(&count, 1)
+	})
+	assert.NoError(t, err)
+
+	time.Sleep(110 * time.Millisecond)
+	job.Stop()
+	got := atomic.LoadInt32(&count)
+	assert.GreaterOrEqual(t, got, int32(3))
+
+	time.Sleep(60 * time.Millisecond)
+	assert.EqualValues(t, got, atomic.LoadInt32(&count), "job should not run after Stop")
+
+	_, err = p.Every(0, func() {})
+	assert.ErrorIs(t, err, ErrInvalidJobInterval)
+}
+
+func TestEveryOverlapPolicy(t *testing.T) {
+	run := func(policy OverlapPolicy) (started, maxConcurrent int32) {
+		p, _ := NewPool(10)
+		defer p.Release()
+
+		var running int32
+		job, _ := p.Every(10*time.Millisecond, func() {
+			cur := atomic.AddInt32(&running, 1)
+			for {
+				old := atomic.LoadInt32(&maxConcurrent)
+				if cur <= old || atomic.CompareAndSwapInt32(&maxConcurrent, old, cur) {
+					break
+				}
+			}
+			atomic.AddInt32(&started, 1)
+			time.Sleep(35 * time.Millisecond)
+			atomic.AddInt32(&running, -1)
+		}, WithOverlapPolicy(policy))
+
+		time.Sleep(105 * time.Millisecond)
+		job.Stop()
+		time.Sleep(50 * time.Millisecond)
+		return atomic.LoadInt32(&started), atomic.LoadInt32(&maxConcurrent)
+	}
+
+	skipStarted, skipMax := run(OverlapSkip)
+	assert.EqualValues(t, 1, skipMax)
+	assert.LessOrEqual(t, skipStarted, int32(4))
+
+	_, queueMax := run(OverlapQueue)
+	assert.EqualValues(t, 1, queueMax)
+
+	_, allowMax := run(OverlapAllow)
+	assert.Greater(t, allowMax, int32(1))
+}
+
+func TestEverySaturatedPool(t *testing.T) {
+	p, _ := NewPool(1, WithNonblocking(true))
+	defer p.Release()
+
+	var count int32
+	job, err := p.Every(20*time.Millisecond, func() {
+		atomic.AddInt32(&count, 1)
+	})
+	assert.NoError(t, err)
+	defer job.Stop()
+
+	// 滿載期間的觸發只會保留一次在佇列中等待
+	release := occupyPool(p, 1)
+	time.Sleep(100 * time.Millisecond)
+	assert.LessOrEqual(t, p.Queued(), 1)
+	release()
+
+	// 滿載之後還會繼續觸發
+	got := atomic.LoadInt32(&count)
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(&count) >= got+3 }, time.Second, 10*time.Millisecond)
+}
+
+func TestEveryStopOnRelease(t *testing.T) {
+	p, _ := NewPool(10)
+
+	var count int32
+	_, _ = p.Every(10*time.Millisecond, func() {
+		atomic.AddInt32(&count, 1)
+	}, WithJitter(5*time.Millisecond))
+	time.Sleep(50 * time.Millisecond)
+	p.Release()
+
+	// 等待 Release 之前已經在執行的任務完成
+	time.Sleep(20 * time.Millisecond)
+	got := atomic.LoadInt32(&count)
+	assert.Greater(t, got, int32(0))
+
+	p.Reboot()
+	defer p.Release()
+	time.Sleep(50 * time.Millisecond)
+	assert.EqualValues(t, got, atomic.LoadInt32(&count), "job should stop after Release")
+}
+
+func TestCronJob(t *testing.T) {
+	p, _ := NewPool(10)
+	defer p.Release()
+
+	_, err := p.Cron("61 * * * *", func() {})
+	assert.ErrorIs(t, err, ErrInvalidCronSpec)
+
+	_, err = p.Cron("0 0 30 2 *", func() {})
+	assert.ErrorIs(t, err, ErrInvalidCronSpec)
+
+	job, err := p.Cron("@hourly", func() {})
+	assert.NoError(t, err)
+	job.Stop()
+}
@@ -0,0 +1,120 @@
+package grpool
+
+import "sync"
+
+// 同一個 key 等待執行的任務
+type keyedTasks struct {
+	tasks []func()
+}
+
+// 依照 key 排隊的任務，每個 key 同時只會有一個任務在 Worker 上執行
+type keyedQueue struct {
+	lock sync.Mutex
+	keys map[string]*keyedTasks
+}
+
+// 加入任務，返回 true 代表這個 key 目前沒有在執行，需要派發新的 Worker
+func (kq *keyedQueue) push(key string, task func()) (*keyedTasks, bool) {
+	kq.lock.Lock()
+	defer kq.lock.Unlock()
+
+	if kq.keys == nil {
+		kq.keys = make(map[string]*keyedTasks)
+	}
+	if q, ok := kq.keys[key]; ok {
+		q.tasks = append(q.tasks, task)
+		return q, false
+	}
+	q := &keyedTasks{tasks: []func(){task}}
+	kq.keys[key] = q
+	return q, true
+}
+
+// 取出下一個任務，沒有任務時就清除這個 key 的狀態
+func (kq *keyedQueue) pop(key string, q *keyedTasks) func() {
+	kq.lock.Lock()
+	defer kq.lock.Unlock()
+
+	if len(q.tasks) == 0 {
+		delete(kq.keys, key)
+		return nil
+	}
+	task := q.tasks[0]
+	q.tasks[0] = nil // 避免記憶體溢出
+	q.tasks = q.tasks[1:]
+	return task
+}
+
+// 移除派發失敗的第一個任務，返回 true 代表這段期間還有其他任務排進來，需要繼續派發
+func (kq *keyedQueue) cancelFirst(key string, q *keyedTasks) bool {
+	kq.lock.Lock()
+	defer kq.lock.Unlock()
+
+	q.tasks[0] = nil // 避免記憶體溢出
+	q.tasks = q.tasks[1:]
+	if len(q.tasks) > 0 {
+		return true
+	}
+	if kq.keys[key] == q {
+		delete(kq.keys, key)
+	}
+	return false
+}
+
+// 丟棄這個 key 所有等待中的任務
+func (kq *keyedQueue) drop(key string, q *keyedTasks) {
+	kq.lock.Lock()
+	if kq.keys[key] == q {
+		delete(kq.keys, key)
+	}
+	kq.lock.Unlock()
+}
+
+// 依照 key 排隊執行任務，同一個 key 的任務會依照提交順序執行，且同時只會有一個在執行，
+// 不同的 key 之間則會在 Pool 上平行執行
+func (p *Pool) ScheduleKeyed(key string, task func()) error {
+	if task == nil {
+		return ErrLackPoolFunc
+	}
+	if err := p.checkAccepting(); err != nil {
+		return err
+	}
+
+	q, first := p.keyed.push(key, task)
+	if !first {
+		return nil
+	}
+	run := func() { p.runKeyed(key, q) }
+	if err := p.dispatch(run, !p.currentOptions().Nonblocking); err != nil {
+		// 只移除自己的任務，這段期間排進來的任務已經返回 nil，放進任務佇列等待 Worker
+		if p.keyed.cancelFirst(key, q) {
+			if p.handoff(run) != nil {
+				p.keyed.drop(key, q)
+			}
+		}
+		return err
+	}
+	return nil
+}
+
+// 在同一個 Worker 上依序執行這個 key 的任務，直到佇列清空
+func (p *Pool) runKeyed(key string, q *keyedTasks) {
+	completed := false
+	defer func() {
+		// 任務 panic 時，交給新的 Worker 繼續執行剩下的任務，不等待 Worker，只有 Pool 被關閉時才丟棄
+		if !completed {
+			if err := p.handoff(func() { p.runKeyed(key, q) }); err != nil {
+				p.keyed.drop(key, q)
+			}
+		}
+	}()
+
+	for {
+		task := p.keyed.pop(key, q)
+		if task == nil {
+			completed = true
+			return
+		}
+		task()
+	}
+}
@@ -0,0 +1,112 @@
+package grpool
+
+import (
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScheduleKeyed(t *testing.T) {
+	p, _ := NewPool(size)
+	defer p.Release()
+
+	const keys, tasks = 10, 100
+	var (
+		wg      sync.WaitGroup
+		lock    sync.Mutex
+		order   = make(map[string][]int)
+		running = make([]int32, keys)
+	)
+	for i := 0; i < tasks; i++ {
+		for k := 0; k < keys; k++ {
+			i, k, key := i, k, strconv.Itoa(k)
+			wg.Add(1)
+			err := p.ScheduleKeyed(key, func() {
+				defer wg.Done()
+				assert.EqualValues(t, 1, atomic.AddInt32(&running[k], 1), "tasks of the same key should not run concurrently")
+				time.Sleep(time.Microsecond)
+				lock.Lock()
+				order[key] = append(order[key], i)
+				lock.Unlock()
+				atomic.AddInt32(&running[k], -1)
+			})
+			assert.NoError(t, err)
+		}
+	}
+	wg.Wait()
+
+	for k := 0; k < keys; k++ {
+		got := order[strconv.Itoa(k)]
+		assert.Len(t, got, tasks)
+		for i := range got {
+			assert.EqualValues(t, i, got[i], "tasks of the same key should run in order")
+		}
+	}
+
+	// 閒置的 key 會被清除
+	time.Sleep(10 * time.Millisecond)
+	p.keyed.lock.Lock()
+	assert.Empty(t, p.keyed.keys)
+	p.keyed.lock.Unlock()
+}
+
+func TestScheduleKeyedPanic(t *testing.T) {
+	p, _ := NewPool(size, WithPanicHandler(func(interface{}) {}))
+	defer p.Release()
+
+	done := make(chan struct{})
+	_ = p.ScheduleKeyed("key", demoPoolFuncWithPanic)
+	_ = p.ScheduleKeyed("key", func() { close(done) })
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("tasks after a panic should keep running")
+	}
+}
+
+func TestScheduleKeyedRejectedKeepsOthers(t *testing.T) {
+	// 第一次取樣時等待 gate，並回報超過上限，讓第一個任務派發失敗
+	entered, gate := make(chan struct{}), make(chan struct{})
+	var sampled int32
+	p, _ := NewPool(1, WithNonblocking(true), WithMemoryLimit(MemoryLimit{
+		SoftLimit: 100,
+		Interval:  time.Nanosecond,
+		Usage: func() uint64 {
+			if atomic.AddInt32(&sampled, 1) == 1 {
+				close(entered)
+				<-gate
+				return 1000
+			}
+			return 0
+		},
+	}))
+	defer p.Release()
+
+	first, second := make(chan struct{}), make(chan struct{})
+	errs := make(chan error, 1)
+	go func() {
+		errs <- p.ScheduleKeyed("key", func() { close(first) })
+	}()
+	<-entered
+
+	// 第一個任務還在派發時排進來的任務已經返回 nil
+	assert.NoError(t, p.ScheduleKeyed("key", func() { close(second) }))
+	close(gate)
+	assert.Error(t, <-errs)
+
+	select {
+	case <-second:
+	case <-time.After(time.Second):
+		t.Fatal("accepted task should run after the first one is rejected")
+	}
+	select {
+	case <-first:
+		t.Fatal("rejected task should not run")
+	default:
+	}
+}
@@ -0,0 +1,244 @@
+package grpool
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// 依照任務的來回時間 (RTT) 調整併發上限，概念來自 Netflix 的 concurrency-limits
+type ConcurrencyLimiter interface {
+	// 目前的併發上限，至少為 1
+	Limit() int
+
+	// 任務完成時回報執行時間，inflight 為任務完成前正在執行的任務數量
+	OnSample(rtt time.Duration, inflight int)
+}
+
+// 把上限限制在 [min, max] 之間
+func clampLimit(limit float64, min, max int) float64 {
+	if limit < float64(min) {
+		return float64(min)
+	}
+	if limit > float64(max) {
+		return float64(max)
+	}
+	return limit
+}
+
+// Vegas 的設定
+type VegasOptions struct {
+	// 初始上限，預設為 20
+	InitialLimit int
+
+	// 上限的範圍，預設為 [1, 1000]
+	MinLimit int
+	MaxLimit int
+
+	// 新上限的權重，範圍 (0, 1]，預設為 1
+	Smoothing float64
+
+	// 每隔多少個樣本重新探測無負載時的 RTT，預設為 1000
+	ProbeInterval int
+}
+
+// 以 TCP Vegas 的方式估計排隊中的任務數量:
+// queue = limit * (1 - rttNoLoad / rtt)，排隊少時增加上限，排隊多時減少上限
+type VegasLimiter struct {
+	lock sync.Mutex
+	opts VegasOptions
+
+	limit float64
+
+	// 觀察到的最小 RTT，視為沒有負載時的 RTT
+	rttNoLoad time.Duration
+
+	// 距離上一次重新探測的樣本數量
+	samples int
+}
+
+// 初始化 VegasLimiter
+func NewVegasLimiter(opts VegasOptions) *VegasLimiter {
+	if opts.MinLimit <= 0 {
+		opts.MinLimit = 1
+	}
+	if opts.MaxLimit < opts.MinLimit {
+		opts.MaxLimit = 1000
+		if opts.MaxLimit < opts.MinLimit {
+			opts.MaxLimit = opts.MinLimit
+		}
+	}
+	if opts.InitialLimit <= 0 {
+		opts.InitialLimit = 20
+	}
+	if opts.Smoothing <= 0 || opts.Smoothing > 1 {
+		opts.Smoothing = 1
+	}
+	if opts.ProbeInterval <= 0 {
+		opts.ProbeInterval = 1000
+	}
+	return &VegasLimiter{
+		opts:  opts,
+		limit: clampLimit(float64(opts.InitialLimit), opts.MinLimit, opts.MaxLimit),
+	}
+}
+
+func (vl *VegasLimiter) Limit() int {
+	vl.lock.Lock()
+	defer vl.lock.Unlock()
+	return int(vl.limit)
+}
+
+func (vl *VegasLimiter) OnSample(rtt time.Duration, inflight int) {
+	if rtt <= 0 {
+		return
+	}
+
+	vl.lock.Lock()
+	defer vl.lock.Unlock()
+
+	// 定期重新探測，避免下游變慢後一直使用過時的 rttNoLoad
+	vl.samples++
+	if vl.samples >= vl.opts.ProbeInterval {
+		vl.samples = 0
+		vl.rttNoLoad = 0
+	}
+	if vl.rttNoLoad == 0 || rtt < vl.rttNoLoad {
+		vl.rttNoLoad = rtt
+		return
+	}
+
+	// 使用量不到一半時，RTT 無法反映上限是否合適
+	if float64(inflight)*2 < vl.limit {
+		return
+	}
+
+	queue := math.Ceil(vl.limit * (1 - float64(vl.rttNoLoad)/float64(rtt)))
+	log := math.Max(1, math.Log10(vl.limit))
+	alpha, beta := 3*log, 6*log
+
+	newLimit := vl.limit
+	switch {
+	case queue <= log:
+		newLimit += beta
+	case queue < alpha:
+		newLimit += log
+	case queue > beta:
+		newLimit -= log
+	default:
+		return
+	}
+
+	newLimit = vl.limit*(1-vl.opts.Smoothing) + newLimit*vl.opts.Smoothing
+	vl.limit = clampLimit(newLimit, vl.opts.MinLimit, vl.opts.MaxLimit)
+}
+
+// Gradient2 的設定
+type Gradient2Options struct {
+	// 初始上限，預設為 20
+	InitialLimit int
+
+	// 上限的範圍，預設為 [1, 1000]
+	MinLimit int
+	MaxLimit int
+
+	// 新上限的權重，範圍 (0, 1]，預設為 0.2
+	Smoothing float64
+
+	// 長期 RTT 的平均樣本數量，預設為 600
+	LongWindow int
+
+	// 可以容忍短期 RTT 比長期 RTT 高出的倍數，預設為 1.5
+	RttTolerance float64
+
+	// 允許排隊的數量，預設為上限的平方根
+	QueueSize func(limit int) int
+}
+
+// 比較短期與長期 RTT 的梯度來調整上限:
+// limit = limit * clamp(tolerance * longRtt / shortRtt, 0.5, 1) + queueSize
+type Gradient2Limiter struct {
+	lock sync.Mutex
+	opts Gradient2Options
+
+	limit float64
+
+	// 長期 RTT 的指數移動平均
+	longRtt float64
+
+	// 已經收到的樣本數量，在 LongWindow 之前使用簡單平均暖機
+	samples int
+}
+
+// 初始化 Gradient2Limiter
+func NewGradient2Limiter(opts Gradient2Options) *Gradient2Limiter {
+	if opts.MinLimit <= 0 {
+		opts.MinLimit = 1
+	}
+	if opts.MaxLimit < opts.MinLimit {
+		opts.MaxLimit = 1000
+		if opts.MaxLimit < opts.MinLimit {
+			opts.MaxLimit = opts.MinLimit
+		}
+	}
+	if opts.InitialLimit <= 0 {
+		opts.InitialLimit = 20
+	}
+	if opts.Smoothing <= 0 || opts.Smoothing > 1 {
+		opts.Smoothing = 0.2
+	}
+	if opts.LongWindow <= 0 {
+		opts.LongWindow = 600
+	}
+	if opts.RttTolerance < 1 {
+		opts.RttTolerance = 1.5
+	}
+	if opts.QueueSize == nil {
+		opts.QueueSize = func(limit int) int {
+			return int(math.Max(1, math.Sqrt(float64(limit))))
+		}
+	}
+	return &Gradient2Limiter{
+		opts:  opts,
+		limit: clampLimit(float64(opts.InitialLimit), opts.MinLimit, opts.MaxLimit),
+	}
+}
+
+func (gl *Gradient2Limiter) Limit() int {
+	gl.lock.Lock()
+	defer gl.lock.Unlock()
+	return int(gl.limit)
+}
+
+func (gl *Gradient2Limiter) OnSample(rtt time.Duration, inflight int) {
+	if rtt <= 0 {
+		return
+	}
+
+	gl.lock.Lock()
+	defer gl.lock.Unlock()
+
+	shortRtt := float64(rtt)
+	gl.samples++
+	if gl.samples <= gl.opts.LongWindow {
+		gl.longRtt += (shortRtt - gl.longRtt) / float64(gl.samples)
+	} else {
+		factor := 2 / float64(gl.opts.LongWindow+1)
+		gl.longRtt = gl.longRtt*(1-factor) + shortRtt*factor
+	}
+
+	// 長期 RTT 遠高於短期 RTT 時代表負載已經下降，讓長期 RTT 更快回復
+	if gl.longRtt/shortRtt > 2 {
+		gl.longRtt *= 0.95
+	}
+
+	// 使用量不到一半時，RTT 無法反映上限是否合適
+	if float64(inflight)*2 < gl.limit {
+		return
+	}
+
+	gradient := math.Max(0.5, math.Min(1, gl.opts.RttTolerance*gl.longRtt/shortRtt))
+	newLimit := gl.limit*gradient + float64(gl.opts.QueueSize(int(gl.limit)))
+	newLimit = gl.limit*(1-gl.opts.Smoothing) + newLimit*gl.opts.Smoothing
+	gl.limit = clampLimit(newLimit, gl.opts.MinLimit, gl.opts.MaxLimit)
+}
@@ -0,0 +1,106 @@
+package grpool
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// 模擬下游的延遲: 併發數量超過 capacity 之後，每多一個任務延遲就增加
+func simulateLatency(inflight, capacity int) time.Duration {
+	base := 10 * time.Millisecond
+	if inflight <= capacity {
+		return base
+	}
+	return base * time.Duration(inflight) / time.Duration(capacity)
+}
+
+func TestVegasLimiter(t *testing.T) {
+	l := NewVegasLimiter(VegasOptions{InitialLimit: 10, MaxLimit: 200})
+	assert.Equal(t, 10, l.Limit())
+
+	// 下游沒有壅塞時增加上限
+	for i := 0; i < 20; i++ {
+		l.OnSample(10*time.Millisecond, l.Limit())
+	}
+	assert.Greater(t, l.Limit(), 10)
+
+	// 使用量不到一半時不調整
+	limit := l.Limit()
+	l.OnSample(50*time.Millisecond, 1)
+	assert.Equal(t, limit, l.Limit())
+
+	// 下游壅塞時，上限收斂到下游能承受的併發數量附近
+	for i := 0; i < 500; i++ {
+		inflight := l.Limit()
+		l.OnSample(simulateLatency(inflight, 50), inflight)
+	}
+	assert.InDelta(t, 50, l.Limit(), 25)
+}
+
+func TestGradient2Limiter(t *testing.T) {
+	l := NewGradient2Limiter(Gradient2Options{InitialLimit: 10, MaxLimit: 200, LongWindow: 100})
+	assert.Equal(t, 10, l.Limit())
+
+	// 下游沒有壅塞時增加上限
+	for i := 0; i < 50; i++ {
+		l.OnSample(10*time.Millisecond, l.Limit())
+	}
+	assert.Greater(t, l.Limit(), 10)
+
+	// RTT 突然變高時減少上限
+	limit := l.Limit()
+	for i := 0; i < 10; i++ {
+		l.OnSample(100*time.Millisecond, l.Limit())
+	}
+	assert.Less(t, l.Limit(), limit)
+}
+
+// 固定上限的 ConcurrencyLimiter
+type fixedLimiter struct {
+	limit   int
+	samples int32
+}
+
+func (fl *fixedLimiter) Limit() int { return fl.limit }
+
+func (fl *fixedLimiter) OnSample(time.Duration, int) { atomic.AddInt32(&fl.samples, 1) }
+
+func TestGrPoolWithConcurrencyLimiter(t *testing.T) {
+	limiter := &fixedLimiter{limit: 3}
+	p, _ := NewPool(10, WithConcurrencyLimiter(limiter))
+	defer p.Release()
+	assert.Equal(t, 3, p.Limit())
+
+	var (
+		wg            sync.WaitGroup
+		running, peak int32
+	)
+	for i := 0; i < 30; i++ {
+		wg.Add(1)
+		_ = p.Schedule(func() {
+			defer wg.Done()
+			cur := atomic.AddInt32(&running, 1)
+			for {
+				old := atomic.LoadInt32(&peak)
+				if cur <= old || atomic.CompareAndSwapInt32(&peak, old, cur) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			atomic.AddInt32(&running, -1)
+		})
+	}
+	wg.Wait()
+
+	assert.LessOrEqual(t, atomic.LoadInt32(&peak), int32(3))
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(&limiter.samples) == 30 }, time.Second, time.Millisecond)
+
+	// 上限高於容量時以容量為準
+	p2, _ := NewPool(2, WithConcurrencyLimiter(&fixedLimiter{limit: 10}))
+	defer p2.Release()
+	assert.Equal(t, 2, p2.Limit())
+}
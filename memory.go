@@ -0,0 +1,128 @@
+package grpool
+
+import (
+	"math"
+	"runtime/debug"
+	"runtime/metrics"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// 依照記憶體使用量決定是否派發新任務的設定
+type MemoryLimit struct {
+	// 軟性上限，單位為 bytes，0 代表使用 debug.SetMemoryLimit 設定值乘上 Ratio，兩者都沒有設定時不限制
+	SoftLimit uint64
+
+	// 沒有設定 SoftLimit 時，佔 debug.SetMemoryLimit 設定值的比例，範圍 (0, 1]，預設為 0.9
+	Ratio float64
+
+	// 取得目前的記憶體使用量，預設讀取 runtime/metrics 的 heap live bytes
+	Usage func() uint64
+
+	// 若設定為 true，超過上限時 Blocking 模式的呼叫會等待使用量下降，否則返回 ErrMemoryLimitExceeded
+	Block bool
+
+	// 重新讀取使用量的頻率，也是 Block 時重新檢查的頻率，預設為 10 毫秒
+	Interval time.Duration
+}
+
+// runtime/metrics 中 heap live bytes 的名稱，舊版本的 Go 沒有或還沒有 GC 過時改用 heap objects bytes
+var heapMetrics = []string{"/gc/heap/live:bytes", "/memory/classes/heap/objects:bytes"}
+
+// 讀取 heap 中存活物件佔用的記憶體
+func heapLiveBytes() uint64 {
+	sample := make([]metrics.Sample, 1)
+	for _, name := range heapMetrics {
+		sample[0].Name = name
+		metrics.Read(sample)
+		if sample[0].Value.Kind() == metrics.KindUint64 && sample[0].Value.Uint64() > 0 {
+			return sample[0].Value.Uint64()
+		}
+	}
+	return 0
+}
+
+// 定期取樣記憶體使用量，判斷是否超過上限
+type memoryGuard struct {
+	cfg MemoryLimit
+
+	lock sync.Mutex
+
+	// 上一次取樣的時間
+	sampled int64
+
+	// 上一次取樣是否超過上限
+	exceeded int32
+}
+
+func newMemoryGuard(cfg MemoryLimit) *memoryGuard {
+	if cfg.Ratio <= 0 || cfg.Ratio > 1 {
+		cfg.Ratio = 0.9
+	}
+	if cfg.Usage == nil {
+		cfg.Usage = heapLiveBytes
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = 10 * time.Millisecond
+	}
+	return &memoryGuard{cfg: cfg}
+}
+
+// 目前的上限，0 代表不限制
+func (mg *memoryGuard) limit() uint64 {
+	if mg.cfg.SoftLimit > 0 {
+		return mg.cfg.SoftLimit
+	}
+	// 傳入負數只會讀取目前的設定值，沒有設定時為 math.MaxInt64
+	limit := debug.SetMemoryLimit(-1)
+	if limit <= 0 || limit == math.MaxInt64 {
+		return 0
+	}
+	return uint64(float64(limit) * mg.cfg.Ratio)
+}
+
+// 使用量是否超過上限，距離上一次取樣不到 Interval 時直接使用上一次的結果
+func (mg *memoryGuard) over() bool {
+	now := time.Now().UnixNano()
+	if now-atomic.LoadInt64(&mg.sampled) < int64(mg.cfg.Interval) {
+		return atomic.LoadInt32(&mg.exceeded) == 1
+	}
+
+	mg.lock.Lock()
+	defer mg.lock.Unlock()
+	// 其他 goroutine 可能已經取樣過
+	if now-atomic.LoadInt64(&mg.sampled) < int64(mg.cfg.Interval) {
+		return atomic.LoadInt32(&mg.exceeded) == 1
+	}
+
+	var exceeded int32
+	if limit := mg.limit(); limit > 0 && mg.cfg.Usage() > limit {
+		exceeded = 1
+	}
+	atomic.StoreInt32(&mg.exceeded, exceeded)
+	atomic.StoreInt64(&mg.sampled, now)
+	return exceeded == 1
+}
+
+// 記憶體沒有超過上限就返回 true，超過上限時依照設定等待使用量下降，
+// 不能等待或等待中 Pool 被關閉時返回 false
+func (p *Pool) waitMemory(block bool) bool {
+	mg := p.memory
+	if !mg.over() {
+		return true
+	}
+	if !block || !mg.cfg.Block {
+		return false
+	}
+
+	atomic.AddInt32(&p.waiting, 1)
+	defer p.doneWaiting()
+	for mg.over() {
+		if p.IsClosed() {
+			return false
+		}
+		time.Sleep(mg.cfg.Interval)
+	}
+	return !p.IsClosed()
+}
@@ -0,0 +1,96 @@
+package grpool
+
+import (
+	"runtime/debug"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryLimitReject(t *testing.T) {
+	var usage uint64 = 100
+	p, err := NewPool(10, WithMemoryLimit(MemoryLimit{
+		SoftLimit: 1000,
+		Usage:     func() uint64 { return atomic.LoadUint64(&usage) },
+		Interval:  time.Millisecond,
+	}), WithRejectionPolicy(CallerRunsPolicy))
+	assert.NoError(t, err)
+	defer p.Release()
+
+	assert.NoError(t, p.Schedule(func() {}))
+
+	// 超過上限時不會交給 CallerRunsPolicy 執行
+	atomic.StoreUint64(&usage, 2000)
+	time.Sleep(2 * time.Millisecond)
+	ran := false
+	assert.ErrorIs(t, p.Schedule(func() { ran = true }), ErrMemoryLimitExceeded)
+	assert.False(t, ran)
+
+	atomic.StoreUint64(&usage, 100)
+	time.Sleep(2 * time.Millisecond)
+	assert.NoError(t, p.Schedule(func() {}))
+}
+
+func TestMemoryLimitBlock(t *testing.T) {
+	var usage uint64 = 2000
+	p, err := NewPool(10, WithMemoryLimit(MemoryLimit{
+		SoftLimit: 1000,
+		Usage:     func() uint64 { return atomic.LoadUint64(&usage) },
+		Block:     true,
+		Interval:  time.Millisecond,
+	}))
+	assert.NoError(t, err)
+	defer p.Release()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- p.Schedule(func() {})
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("Schedule should block while memory is above the limit")
+	case <-time.After(20 * time.Millisecond):
+	}
+	assert.Equal(t, 1, p.Waiting())
+
+	// 使用量下降後繼續派發
+	atomic.StoreUint64(&usage, 100)
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("Schedule should resume once memory drops")
+	}
+	assert.Equal(t, 0, p.Waiting())
+}
+
+func TestMemoryLimitRuntimeLimit(t *testing.T) {
+	// 沒有設定 SoftLimit 時使用 debug.SetMemoryLimit 的值
+	old := debug.SetMemoryLimit(1 << 40)
+	defer debug.SetMemoryLimit(old)
+
+	var usage uint64 = 1 << 39
+	p, err := NewPool(10, WithMemoryLimit(MemoryLimit{
+		Ratio:    0.9,
+		Usage:    func() uint64 { return atomic.LoadUint64(&usage) },
+		Interval: time.Millisecond,
+	}))
+	assert.NoError(t, err)
+	defer p.Release()
+	assert.NoError(t, p.Schedule(func() {}))
+
+	atomic.StoreUint64(&usage, 1<<40-1)
+	time.Sleep(2 * time.Millisecond)
+	assert.ErrorIs(t, p.Schedule(func() {}), ErrMemoryLimitExceeded)
+
+	// 沒有設定任何上限時不限制
+	debug.SetMemoryLimit(old)
+	time.Sleep(2 * time.Millisecond)
+	assert.NoError(t, p.Schedule(func() {}))
+
+	// 預設讀取 runtime/metrics
+	assert.Greater(t, heapLiveBytes(), uint64(0))
+}
@@ -0,0 +1,64 @@
+package grpool
+
+import "sync/atomic"
+
+// 在任務裡面對同一個 Pool 提交任務，且沒有可用的 Worker 時的處理方式
+type NestedPolicy int
+
+const (
+	// 與一般提交相同，Blocking 模式下會等待 Worker，Pool 滿載時會因為自己佔用 Worker 而死鎖
+	NestedBlock NestedPolicy = iota
+
+	// 直接在提交的 Worker 上執行
+	NestedCallerRuns
+
+	// 放進任務佇列，等提交的 Worker 或其他 Worker 空出來再執行，不受 TaskQueueSize 限制
+	NestedQueue
+)
+
+// 是否需要記錄 Worker 所在的 goroutine
+func (p *Pool) tracksWorkers() bool {
+	return p.currentOptions().WorkStealing || p.currentOptions().NestedPolicy != NestedBlock
+}
+
+// 獲取提交任務的 goroutine 所在的 Worker，不是從 Worker 裡面提交就返回 nil，
+// 解析 goroutine id 需要呼叫 runtime.Stack，沒有任務在執行時不可能從 Worker 裡面提交，可以直接略過
+func (p *Pool) nestedWorker() *Worker {
+	if !p.tracksWorkers() || atomic.LoadInt32(&p.inflight) == 0 {
+		return nil
+	}
+	return p.stealing.current()
+}
+
+// 從 Worker 裡面提交且容量不足時，是否要直接在提交的 Worker 上執行，
+// 否則放進等待的佇列後直接返回，不等待 Worker，用於無法透過 scheduleNested 處理的加權與 tenant 任務
+func (p *Pool) nestedCallerRuns() bool {
+	return p.currentOptions().WorkStealing || p.currentOptions().NestedPolicy == NestedCallerRuns
+}
+
+// 處理從 Worker 裡面提交的任務，有可用的 Worker 就直接派發，否則依照 NestedPolicy 處理，
+// 不論 Pool 是否為 Nonblocking 都不會返回 ErrPoolOverload
+func (p *Pool) scheduleNested(task func(), internal bool) error {
+	w, queued := p.getWorker(task, false, internal)
+	if w != nil {
+		w.inputFunc(task)
+		return nil
+	}
+	if queued {
+		return nil
+	}
+
+	switch p.currentOptions().NestedPolicy {
+	case NestedCallerRuns:
+		task()
+	case NestedQueue:
+		p.lock.Lock()
+		if p.IsClosed() {
+			p.lock.Unlock()
+			return ErrPoolClosed
+		}
+		p.tasks.forcePush(task, internal)
+		p.lock.Unlock()
+	}
+	return nil
+}
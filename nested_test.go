@@ -0,0 +1,163 @@
+package grpool
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// 每個 Worker 都在任務裡面提交任務並等待完成
+func scheduleNestedAtCapacity(p *Pool, workers int) (done chan struct{}, errs chan error) {
+	return submitNestedAtCapacity(p, workers, p.Schedule)
+}
+
+// 與 scheduleNestedAtCapacity 相同，巢狀任務使用 submit 提交
+func submitNestedAtCapacity(p *Pool, workers int, submit func(func()) error) (done chan struct{}, errs chan error) {
+	var wg sync.WaitGroup
+	done = make(chan struct{})
+	errs = make(chan error, workers)
+
+	// 確保所有 Worker 都被佔用後才提交巢狀任務
+	var started sync.WaitGroup
+	started.Add(workers)
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		_ = p.Schedule(func() {
+			defer wg.Done()
+			started.Done()
+			started.Wait()
+
+			inner := make(chan struct{})
+			err := submit(func() { close(inner) })
+			errs <- err
+			if err == nil {
+				<-inner
+			}
+		})
+	}
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	return
+}
+
+func TestNestedBlockHangs(t *testing.T) {
+	const workers = 4
+	p, _ := NewPool(workers)
+
+	done, errs := scheduleNestedAtCapacity(p, workers)
+	select {
+	case <-done:
+		t.Fatal("nested submission at capacity is expected to hang with NestedBlock")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// Release 會喚醒所有等待中的提交
+	p.Release()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Release should wake up blocked nested submissions")
+	}
+	for i := 0; i < workers; i++ {
+		assert.ErrorIs(t, <-errs, ErrPoolOverload)
+	}
+}
+
+func TestNestedCallerRuns(t *testing.T) {
+	const workers = 4
+	p, _ := NewPool(workers, WithNestedPolicy(NestedCallerRuns))
+	defer p.Release()
+
+	done, errs := scheduleNestedAtCapacity(p, workers)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("nested submission deadlocked")
+	}
+	for i := 0; i < workers; i++ {
+		assert.NoError(t, <-errs)
+	}
+}
+
+func TestNestedQueue(t *testing.T) {
+	const workers = 4
+	p, _ := NewPool(workers, WithNestedPolicy(NestedQueue), WithNonblocking(true))
+	defer p.Release()
+
+	var wg sync.WaitGroup
+	block := make(chan struct{})
+	wg.Add(workers * 2)
+	for i := 0; i < workers; i++ {
+		_ = p.Schedule(func() {
+			defer wg.Done()
+			<-block
+			// 不受 Nonblocking 影響，放進佇列等待執行
+			assert.NoError(t, p.Schedule(wg.Done))
+		})
+	}
+	assert.ErrorIs(t, p.Schedule(demoFunc), ErrPoolOverload, "submissions outside workers are not affected")
+	close(block)
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("queued nested tasks were not executed")
+	}
+}
+
+func TestNestedWeightedAndTenant(t *testing.T) {
+	const workers = 4
+	for _, opt := range []Option{WithNestedPolicy(NestedCallerRuns), WithWorkStealing(true)} {
+		p, _ := NewPool(workers, opt)
+		submits := map[string]func(func()) error{
+			"weighted": func(task func()) error { return p.ScheduleWeighted(2, task) },
+			"tenant":   func(task func()) error { return p.ScheduleTenant("a", task) },
+		}
+		for name, submit := range submits {
+			done, errs := submitNestedAtCapacity(p, workers, submit)
+			select {
+			case <-done:
+			case <-time.After(time.Second):
+				t.Fatalf("nested %s submission deadlocked", name)
+			}
+			for i := 0; i < workers; i++ {
+				assert.NoError(t, <-errs)
+			}
+		}
+		p.Release()
+	}
+}
+
+func TestNestedQueueWeightedAndTenant(t *testing.T) {
+	const workers = 4
+	p, _ := NewPool(workers, WithNestedPolicy(NestedQueue), WithNonblocking(true))
+	defer p.Release()
+
+	var ran int32
+	block := make(chan struct{})
+	for i := 0; i < workers; i++ {
+		_ = p.Schedule(func() {
+			<-block
+			// 容量不足時排隊後直接返回，不受 Nonblocking 影響
+			assert.NoError(t, p.ScheduleWeighted(2, func() { atomic.AddInt32(&ran, 1) }))
+			assert.NoError(t, p.ScheduleTenant("a", func() { atomic.AddInt32(&ran, 1) }))
+		})
+	}
+	close(block)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	assert.NoError(t, p.WaitIdle(ctx))
+	assert.EqualValues(t, workers*2, atomic.LoadInt32(&ran))
+}
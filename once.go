@@ -0,0 +1,85 @@
+package grpool
+
+import (
+	"fmt"
+	"sync"
+)
+
+// 同一個 key 正在執行中的任務
+type onceCall struct {
+	wg sync.WaitGroup
+
+	val interface{}
+	err error
+
+	// 共用結果的呼叫數量
+	dups int
+}
+
+// 合併相同 key 的任務，概念與 x/sync/singleflight 相同
+type onceGroup struct {
+	lock  sync.Mutex
+	calls map[string]*onceCall
+}
+
+// 完成任務，喚醒所有等待結果的呼叫
+func (g *onceGroup) finish(key string, c *onceCall) {
+	g.lock.Lock()
+	delete(g.calls, key)
+	g.lock.Unlock()
+	c.wg.Done()
+}
+
+// 將相同 key 的並發呼叫合併成一次在 Worker 上執行，並把結果回傳給所有呼叫者，
+// shared 代表結果是否有被多個呼叫者共用
+func (p *Pool) ScheduleOnce(key string, task func() (interface{}, error)) (v interface{}, err error, shared bool) {
+	if task == nil {
+		return nil, ErrLackPoolFunc, false
+	}
+	if err := p.checkAccepting(); err != nil {
+		return nil, err, false
+	}
+
+	g := &p.once
+	g.lock.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*onceCall)
+	}
+	if c, ok := g.calls[key]; ok {
+		c.dups++
+		g.lock.Unlock()
+		c.wg.Wait()
+		return c.val, c.err, true
+	}
+	c := new(onceCall)
+	c.wg.Add(1)
+	g.calls[key] = c
+	g.lock.Unlock()
+
+	if err := p.dispatch(func() { p.runOnce(key, c, task) }, !p.currentOptions().Nonblocking); err != nil {
+		// 派發失敗時，等待中的呼叫也會拿到相同的錯誤
+		c.err = err
+		g.finish(key, c)
+	} else {
+		c.wg.Wait()
+	}
+
+	g.lock.Lock()
+	shared = c.dups > 0
+	g.lock.Unlock()
+	return c.val, c.err, shared
+}
+
+func (p *Pool) runOnce(key string, c *onceCall, task func() (interface{}, error)) {
+	defer func() {
+		// 任務 panic 時，讓等待中的呼叫拿到錯誤，再交給 Worker 處理 panic
+		if r := recover(); r != nil {
+			c.err = fmt.Errorf("%w: %v", ErrTaskPanicked, r)
+			p.once.finish(key, c)
+			panic(r)
+		}
+	}()
+
+	c.val, c.err = task()
+	p.once.finish(key, c)
+}
@@ -0,0 +1,77 @@
+package grpool
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScheduleOnce(t *testing.T) {
+	p, _ := NewPool(size)
+	defer p.Release()
+
+	var (
+		calls  int32
+		wg     sync.WaitGroup
+		shares int32
+		start  = make(chan struct{})
+	)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			v, err, shared := p.ScheduleOnce("key", func() (interface{}, error) {
+				atomic.AddInt32(&calls, 1)
+				time.Sleep(50 * time.Millisecond)
+				return "value", nil
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, "value", v)
+			if shared {
+				atomic.AddInt32(&shares, 1)
+			}
+		}()
+	}
+	close(start)
+	wg.Wait()
+
+	assert.EqualValues(t, 1, atomic.LoadInt32(&calls), "concurrent calls should be coalesced")
+	assert.EqualValues(t, 10, atomic.LoadInt32(&shares))
+
+	// 前一次完成之後會重新執行
+	v, err, shared := p.ScheduleOnce("key", func() (interface{}, error) {
+		return nil, errors.New("failed")
+	})
+	assert.Nil(t, v)
+	assert.EqualError(t, err, "failed")
+	assert.False(t, shared)
+}
+
+func TestScheduleOncePanic(t *testing.T) {
+	p, _ := NewPool(size, WithPanicHandler(func(interface{}) {}))
+	defer p.Release()
+
+	_, err, _ := p.ScheduleOnce("key", func() (interface{}, error) {
+		panic("error")
+	})
+	assert.ErrorIs(t, err, ErrTaskPanicked)
+}
+
+func TestScheduleOnceOverload(t *testing.T) {
+	p, _ := NewPool(1, WithNonblocking(true))
+	defer p.Release()
+
+	block := make(chan struct{})
+	_ = p.Schedule(func() { <-block })
+	defer close(block)
+
+	_, err, _ := p.ScheduleOnce("key", func() (interface{}, error) {
+		return nil, nil
+	})
+	assert.ErrorIs(t, err, ErrPoolOverload)
+}
@@ -19,6 +19,12 @@ type Options struct {
 	// 過期時間: 用於定時清理過期的 Worker (只要太久沒被使用的 Worker 就會被清理)，預設為 1 秒
 	ExpiryDuration time.Duration
 
+	// 清理過期 Worker 的頻率，預設與 ExpiryDuration 相同
+	CleanInterval time.Duration
+
+	// 每次清理最多 finish 的 Worker 數量，0 代表沒有限制
+	MaxCleanPerTick int
+
 	// 是否提前申請空間，大量執行需求中使用
 	PreAlloc bool
 
@@ -31,6 +37,77 @@ type Options struct {
 
 	// 若設定為 true，Worker 就不會被自動清除
 	DisableClear bool
+
+	// 清理過期的 Worker 時，至少保留的閒置 Worker 數量
+	MinIdleWorkers int
+
+	// 依照負載自動調整過期時間，設定後會取代 ExpiryDuration 作為過期的判斷
+	AdaptiveExpiry *AdaptiveExpiry
+
+	// 依照負載自動調整 Pool 容量
+	Autoscale *Autoscale
+
+	// 依照任務執行時間限制併發數量，有效的上限為 Pool 容量與 Limit() 的較小值
+	ConcurrencyLimiter ConcurrencyLimiter
+
+	// 記憶體使用量超過上限時拒絕或等待新的任務
+	MemoryLimit *MemoryLimit
+
+	// ScheduleTagged 每個 tag 同時執行的上限，沒有設定的 tag 只受 Pool 容量限制
+	TagLimits map[string]int
+
+	// ScheduleTenant 每個 tenant 分配 Worker 的權重，沒有設定的 tenant 權重為 1
+	TenantWeights map[string]int
+
+	// Pool 滿載時，任務排隊等待 Worker 的佇列大小，0 代表不使用佇列
+	TaskQueueSize int
+
+	// 若設定為 true，從任務裡面提交的子任務會放進 Worker 自己的 deque，閒置的 Worker 會互相偷取任務
+	WorkStealing bool
+
+	// 在任務裡面提交任務且沒有可用的 Worker 時的處理方式，預設為 NestedBlock
+	NestedPolicy NestedPolicy
+
+	// Pool 滿載且沒有辦法等待或放進佇列時的處理方式，預設為 AbortPolicy
+	RejectionPolicy RejectionPolicy
+}
+
+// 複製一份設定，map 與指標指向的設定也會一起複製，避免修改到原本的設定
+func (opts *Options) clone() *Options {
+	c := *opts
+	if opts.TagLimits != nil {
+		c.TagLimits = make(map[string]int, len(opts.TagLimits))
+		for tag, limit := range opts.TagLimits {
+			c.TagLimits[tag] = limit
+		}
+	}
+	if opts.TenantWeights != nil {
+		c.TenantWeights = make(map[string]int, len(opts.TenantWeights))
+		for tenant, weight := range opts.TenantWeights {
+			c.TenantWeights[tenant] = weight
+		}
+	}
+	if opts.AdaptiveExpiry != nil {
+		adaptive := *opts.AdaptiveExpiry
+		c.AdaptiveExpiry = &adaptive
+	}
+	if opts.Autoscale != nil {
+		autoscale := *opts.Autoscale
+		c.Autoscale = &autoscale
+	}
+	if opts.MemoryLimit != nil {
+		memory := *opts.MemoryLimit
+		c.MemoryLimit = &memory
+	}
+	return &c
+}
+
+// 可以透過 Reconfigure 在執行中的 Pool 直接變更的設定，欄位的意義與 Options 相同
+type LiveOptions struct {
+	ExpiryDuration time.Duration
+	Nonblocking    bool
+	DisableClear   bool
+	PreAlloc       bool
 }
 
 // 直接傳入 Options
@@ -47,6 +124,68 @@ func WithExpiryDuration(expiryDuration time.Duration) Option {
 	}
 }
 
+// 設定清理過期 Worker 的頻率
+func WithCleanInterval(interval time.Duration) Option {
+	return func(opts *Options) {
+		opts.CleanInterval = interval
+	}
+}
+
+// 設定每次清理最多 finish 的 Worker 數量
+func WithMaxCleanPerTick(max int) Option {
+	return func(opts *Options) {
+		opts.MaxCleanPerTick = max
+	}
+}
+
+// 開啟自動調整過期時間，過期時間會在 [MinExpiry, MaxExpiry] 之間隨著負載變動
+func WithAdaptiveExpiry(adaptive AdaptiveExpiry) Option {
+	return func(opts *Options) {
+		opts.AdaptiveExpiry = &adaptive
+	}
+}
+
+// 開啟自動調整容量，容量會在 [Min, Max] 之間依照 ScalePolicy 變動
+func WithAutoscale(autoscale Autoscale) Option {
+	return func(opts *Options) {
+		opts.Autoscale = &autoscale
+	}
+}
+
+// 設定依照任務執行時間調整併發上限的 ConcurrencyLimiter
+func WithConcurrencyLimiter(limiter ConcurrencyLimiter) Option {
+	return func(opts *Options) {
+		opts.ConcurrencyLimiter = limiter
+	}
+}
+
+// 設定依照記憶體使用量決定是否派發新任務
+func WithMemoryLimit(limit MemoryLimit) Option {
+	return func(opts *Options) {
+		opts.MemoryLimit = &limit
+	}
+}
+
+// 設定 tag 同時執行的上限
+func WithTagLimit(tag string, limit int) Option {
+	return func(opts *Options) {
+		if opts.TagLimits == nil {
+			opts.TagLimits = make(map[string]int)
+		}
+		opts.TagLimits[tag] = limit
+	}
+}
+
+// 設定 tenant 分配 Worker 的權重
+func WithTenantWeight(tenant string, weight int) Option {
+	return func(opts *Options) {
+		if opts.TenantWeights == nil {
+			opts.TenantWeights = make(map[string]int)
+		}
+		opts.TenantWeights[tenant] = weight
+	}
+}
+
 // 設定是否要提前創建空間
 func WithPreAlloc(preAlloc bool) Option {
 	return func(opts *Options) {
@@ -74,3 +213,48 @@ func WithDisableClear(disable bool) Option {
 		opts.DisableClear = disable
 	}
 }
+
+// 設定清理時至少保留的閒置 Worker 數量，避免閒置一段時間後的第一批任務需要重新啟動 goroutine
+func WithMinIdleWorkers(n int) Option {
+	return func(opts *Options) {
+		opts.MinIdleWorkers = n
+	}
+}
+
+// 設定任務佇列大小，Pool 滿載時任務會先放進佇列，等 Worker 空出來再執行
+func WithTaskQueue(size int) Option {
+	return func(opts *Options) {
+		opts.TaskQueueSize = size
+	}
+}
+
+// 是否開啟 work stealing 模式，適合在任務裡面大量提交子任務的 CPU 密集運算
+func WithWorkStealing(workStealing bool) Option {
+	return func(opts *Options) {
+		opts.WorkStealing = workStealing
+	}
+}
+
+// 設定在任務裡面提交任務的處理方式，避免 Pool 滿載時死鎖
+func WithNestedPolicy(policy NestedPolicy) Option {
+	return func(opts *Options) {
+		opts.NestedPolicy = policy
+	}
+}
+
+// 設定任務被拒絕時的處理方式
+func WithRejectionPolicy(policy RejectionPolicy) Option {
+	return func(opts *Options) {
+		opts.RejectionPolicy = policy
+	}
+}
+
+// 自訂任務被拒絕時的處理，Schedule 不會返回錯誤
+func WithRejectionHandler(handler func(task func(), p *Pool)) Option {
+	return func(opts *Options) {
+		opts.RejectionPolicy = func(task func(), p *Pool) error {
+			handler(task, p)
+			return nil
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package grpool
+
+import "sync/atomic"
+
+// 暫停派發任務: Schedule、ScheduleWeighted 與 ScheduleTenant 仍然會接受任務，Blocking 模式會等待、Nonblocking 模式會排隊，
+// 直到 Resume 之前都不會交給 Worker，已經在執行的任務不受影響，閒置的 Worker 仍然會被清理
+func (p *Pool) Pause() {
+	atomic.StoreInt32(&p.paused, 1)
+}
+
+// 恢復派發任務，排隊中與等待中的任務會交給閒置的 Worker 或新的 Worker
+func (p *Pool) Resume() {
+	if !atomic.CompareAndSwapInt32(&p.paused, 1, 0) {
+		return
+	}
+
+	// 派發暫停期間放進佇列的任務
+	for {
+		p.lock.Lock()
+		if p.IsClosed() || p.tasks.len() == 0 {
+			p.lock.Unlock()
+			break
+		}
+		w, spawn := p.reserveWorker()
+		if w == nil && !spawn {
+			p.lock.Unlock()
+			break
+		}
+		task := p.tasks.pop()
+		p.lock.Unlock()
+
+		if spawn {
+			w = p.spawnWorker()
+		}
+		w.inputFunc(task)
+	}
+
+	// 喚醒等待 Worker 的呼叫
+	p.lock.Lock()
+	p.cond.Broadcast()
+	p.lock.Unlock()
+	p.wakeWeighted()
+	p.wakeTenants()
+
+	// 暫停期間留在 deque 中的子任務
+	if p.currentOptions().WorkStealing {
+		for i := atomic.LoadInt32(&p.stealing.pending); i > 0; i-- {
+			p.wakeStealer()
+		}
+	}
+}
+
+// 是否暫停派發任務
+func (p *Pool) Paused() bool {
+	return atomic.LoadInt32(&p.paused) == 1
+}
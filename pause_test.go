@@ -0,0 +1,89 @@
+package grpool
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPauseBlocking(t *testing.T) {
+	p, _ := NewPool(2)
+	defer p.Release()
+	_ = p.Warmup(2)
+
+	p.Pause()
+	assert.True(t, p.Paused())
+
+	ran := make(chan struct{})
+	go func() {
+		assert.NoError(t, p.Schedule(func() { close(ran) }))
+	}()
+	select {
+	case <-ran:
+		t.Fatal("paused pool should not start tasks")
+	case <-time.After(20 * time.Millisecond):
+	}
+	assert.Equal(t, 1, p.Waiting())
+
+	p.Resume()
+	assert.False(t, p.Paused())
+	select {
+	case <-ran:
+	case <-time.After(time.Second):
+		t.Fatal("task should run after Resume")
+	}
+}
+
+func TestPauseNonblocking(t *testing.T) {
+	p, _ := NewPool(2, WithNonblocking(true))
+	defer p.Release()
+
+	p.Pause()
+	var count int32
+	for i := 0; i < 5; i++ {
+		assert.NoError(t, p.Schedule(func() { atomic.AddInt32(&count, 1) }))
+	}
+	time.Sleep(20 * time.Millisecond)
+	assert.EqualValues(t, 0, atomic.LoadInt32(&count))
+	assert.Equal(t, 5, p.Queued())
+
+	p.Resume()
+	time.Sleep(50 * time.Millisecond)
+	assert.EqualValues(t, 5, atomic.LoadInt32(&count))
+	assert.Equal(t, 0, p.Queued())
+}
+
+func TestPauseClearsIdleWorkers(t *testing.T) {
+	p, _ := NewPool(10, WithCleanInterval(10*time.Millisecond), WithExpiryDuration(20*time.Millisecond))
+	defer p.Release()
+	_ = p.Warmup(5)
+
+	p.Pause()
+	defer p.Resume()
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, 0, p.Running())
+}
+
+func TestPauseNonblockingWeightedAndTenant(t *testing.T) {
+	p, _ := NewPool(4, WithNonblocking(true))
+	defer p.Release()
+
+	// 暫停中不拒絕，排隊等 Resume 之後執行
+	p.Pause()
+	var count int32
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, p.ScheduleWeighted(2, func() { atomic.AddInt32(&count, 1) }))
+		assert.NoError(t, p.ScheduleTenant("a", func() { atomic.AddInt32(&count, 1) }))
+	}
+	time.Sleep(20 * time.Millisecond)
+	assert.EqualValues(t, 0, atomic.LoadInt32(&count))
+
+	p.Resume()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	assert.NoError(t, p.WaitIdle(ctx))
+	assert.EqualValues(t, 6, atomic.LoadInt32(&count))
+}
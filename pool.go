@@ -15,12 +15,33 @@ type Pool struct {
 	// 正在執行的goroutine
 	running int32
 
+	// 等待 Worker 的呼叫數量
+	waiting int32
+
+	// 已經派發給 Worker 但還沒完成的任務數量
+	inflight int32
+
 	// 閒置的Workers
 	workers workerQueue
 
+	// 沒有可用的 Worker 時，排隊等待的任務
+	tasks *taskQueue
+
 	// 警告Pool要自己close
 	state int32
 
+	// 串行化 Drain、Release 與 Reboot 的狀態轉換
+	lifecycle sync.Mutex
+
+	// Pool 完全停止後關閉
+	done chan struct{}
+
+	// 是否暫停派發任務
+	paused int32
+
+	// 每次 Release 都會增加，用來辨識 Release 之前建立的延遲任務
+	epoch uint32
+
 	// 鎖
 	lock sync.Locker
 
@@ -31,10 +52,56 @@ type Pool struct {
 	workerCache sync.Pool
 
 	// Clear 是否完成
-	clearDone int32
-	stopClear context.CancelFunc
+	clearDone    int32
+	stopClear    context.CancelFunc
+	clearStopped chan struct{}
+
+	// 自動調整過期時間
+	adaptive *adaptiveCleaner
+
+	// 自動調整容量
+	autoscaler       *autoscaler
+	stopAutoscale    context.CancelFunc
+	autoscaleStopped chan struct{}
+
+	// 任務的執行時間
+	latency latencyStats
+
+	// 依照任務執行時間限制併發數量
+	limiter ConcurrencyLimiter
+
+	// 依照記憶體使用量決定是否派發新任務
+	memory *memoryGuard
+
+	// 加權任務佔用的容量與等待中的加權任務
+	weighted weightedState
+
+	// 依照 tag 分組的任務
+	tagged tagGroup
+
+	// 子 Pool
+	children childGroup
+
+	// 等待 Pool 閒置的呼叫
+	idle idleNotifier
+
+	// 依照 tenant 等待 Worker 的任務
+	tenants tenantScheduler
+
+	// 延遲任務
+	timers timerQueue
+
+	// 依照 key 排隊的任務
+	keyed keyedQueue
+
+	// 合併相同 key 的任務
+	once onceGroup
+
+	// work stealing 或 NestedPolicy 需要記錄的 Worker
+	stealing stealRegistry
 
-	options *Options
+	// 目前的設定，發布之後不再修改，RebootWithOptions 與 Reconfigure 會整份替換
+	options atomic.Pointer[Options]
 }
 
 // 初始化
@@ -43,25 +110,18 @@ func NewPool(size int, options ...Option) (*Pool, error) {
 	// 加載設定
 	opts := loadOptions(options...)
 
-	if !opts.DisableClear {
-		if expiry := opts.ExpiryDuration; expiry < 0 {
-			return nil, ErrInvalidPoolExpiry
-		} else if expiry == 0 {
-			opts.ExpiryDuration = DefaultCleanIntervalTime
-		}
-	}
-
-	// 如果 size 不是一個有效的 Size 就使用 DefaultPoolSize
-	if size <= 0 {
-		size = -1
+	size, err := validateOptions(size, opts)
+	if err != nil {
+		return nil, err
 	}
 
 	// init
 	p := &Pool{
 		capacity: int32(size),
 		lock:     syncx.NewSpinLock(),
-		options:  opts,
+		done:     make(chan struct{}),
 	}
+	p.options.Store(opts)
 
 	p.workerCache.New = func() interface{} {
 		return &Worker{
@@ -72,31 +132,183 @@ func NewPool(size int, options ...Option) (*Pool, error) {
 
 	p.cond = sync.NewCond(p.lock)
 
-	if size == -1 {
-		size = DefaultPoolSize
-	}
-	p.workers = newWorkerCircularQueue(size, p.options.PreAlloc)
+	p.applyOptions()
 
 	// 定期清理過期的worker，節省系統資源
 	p.goClear()
 
+	// 定期調整容量
+	p.goAutoscale()
+
 	return p, nil
 }
 
+// 檢查 size 與設定是否能建立 Pool，返回的錯誤與 NewPool 相同
+func ValidateOptions(size int, options ...Option) error {
+	_, err := validateOptions(size, loadOptions(options...))
+	return err
+}
+
+// 檢查設定並補上預設值，返回調整後的容量，-1 代表沒有上限，NewPool 與 RebootWithOptions 共用
+func validateOptions(size int, opts *Options) (int, error) {
+	if opts.ExpiryDuration < 0 {
+		return 0, ErrInvalidPoolExpiry
+	} else if opts.ExpiryDuration == 0 && !opts.DisableClear {
+		opts.ExpiryDuration = DefaultCleanIntervalTime
+	}
+
+	if opts.CleanInterval < 0 {
+		return 0, ErrInvalidCleanInterval
+	}
+
+	if opts.MaxCleanPerTick < 0 {
+		return 0, ErrInvalidMaxCleanPerTick
+	}
+
+	if adaptive := opts.AdaptiveExpiry; adaptive != nil {
+		if adaptive.MinExpiry <= 0 || adaptive.MaxExpiry < adaptive.MinExpiry {
+			return 0, ErrInvalidPoolExpiry
+		}
+	}
+
+	if opts.TaskQueueSize < 0 {
+		return 0, ErrInvalidTaskQueueSize
+	}
+
+	if opts.MinIdleWorkers < 0 {
+		return 0, ErrInvalidMinIdleWorkers
+	}
+
+	if opts.NestedPolicy < NestedBlock || opts.NestedPolicy > NestedQueue {
+		return 0, ErrInvalidNestedPolicy
+	}
+
+	if ml := opts.MemoryLimit; ml != nil {
+		if ml.Ratio < 0 || ml.Ratio > 1 || ml.Interval < 0 {
+			return 0, ErrInvalidMemoryLimit
+		}
+	}
+
+	for _, limit := range opts.TagLimits {
+		if limit < 0 {
+			return 0, ErrInvalidTagLimit
+		}
+	}
+
+	for _, weight := range opts.TenantWeights {
+		if weight <= 0 {
+			return 0, ErrInvalidTenantWeight
+		}
+	}
+
+	// 開啟自動調整容量時，初始容量會被限制在 [Min, Max] 之間
+	if as := opts.Autoscale; as != nil {
+		if as.Min <= 0 || as.Max < as.Min {
+			return 0, ErrInvalidAutoscale
+		}
+		if size <= 0 || size > as.Max {
+			size = as.Max
+		} else if size < as.Min {
+			size = as.Min
+		}
+	}
+
+	// 如果 size 不是一個有效的 Size 就代表沒有上限
+	if size <= 0 {
+		// 沒有上限時無法提前申請空間
+		if opts.PreAlloc {
+			return 0, ErrInvalidPreAllocSize
+		}
+		size = -1
+	}
+
+	// 保留的閒置 Worker 不能超過最大的容量
+	max := size
+	if opts.Autoscale != nil {
+		max = opts.Autoscale.Max
+	}
+	if max > 0 && opts.MinIdleWorkers > max {
+		return 0, ErrInvalidMinIdleWorkers
+	}
+	return size, nil
+}
+
+// 依照目前的設定建立 Worker 佇列與各項功能的狀態，Pool 開啟後呼叫時必須持有 p.lock
+func (p *Pool) applyOptions() {
+	opts := p.currentOptions()
+	p.workers = p.newWorkerQueue(opts)
+	p.tasks = newTaskQueue(opts.TaskQueueSize)
+
+	p.adaptive = nil
+	if opts.AdaptiveExpiry != nil {
+		p.adaptive = newAdaptiveCleaner(*opts.AdaptiveExpiry)
+	}
+	p.autoscaler = nil
+	if opts.Autoscale != nil {
+		p.autoscaler = newAutoscaler(*opts.Autoscale)
+	}
+	p.limiter = opts.ConcurrencyLimiter
+	p.memory = nil
+	if opts.MemoryLimit != nil {
+		p.memory = newMemoryGuard(*opts.MemoryLimit)
+	}
+}
+
+// 依照容量與 PreAlloc 建立閒置 Worker 的佇列
+func (p *Pool) newWorkerQueue(opts *Options) workerQueue {
+	size := p.Cap()
+	// 容量會在 Max 以內變動，閒置的 Worker 也要放得下
+	if opts.Autoscale != nil {
+		size = opts.Autoscale.Max
+	}
+	if size == -1 {
+		// 沒有上限時依照閒置的 Worker 數量調整空間
+		return newWorkerGrowableQueue()
+	}
+	return newWorkerCircularQueue(size, opts.PreAlloc)
+}
+
 // 開啟一個 goroutine 定時清理過期的 workers
 func (p *Pool) goClear() {
-	if p.options.DisableClear {
+	if p.currentOptions().DisableClear {
 		return
 	}
 
 	var ctx context.Context
 	ctx, p.stopClear = context.WithCancel(context.Background())
-	go p.ClearStaleWorkers(ctx)
+	stopped := make(chan struct{})
+	p.clearStopped = stopped
+	go func() {
+		defer close(stopped)
+		p.ClearStaleWorkers(ctx)
+	}()
 }
 
-// 定時清理過期的 workers
+// 停止清理的 goroutine 並等待退出，呼叫時必須持有 p.lifecycle
+func (p *Pool) stopClearing() {
+	if p.stopClear != nil {
+		p.stopClear()
+		p.stopClear = nil
+	}
+	if p.clearStopped != nil {
+		<-p.clearStopped
+		p.clearStopped = nil
+	}
+	atomic.StoreInt32(&p.clearDone, 0)
+}
+
+// 清理過期 Worker 的頻率，沒有設定 CleanInterval 時與 ExpiryDuration 相同
+func (p *Pool) cleanInterval() time.Duration {
+	opts := p.currentOptions()
+	if interval := opts.CleanInterval; interval > 0 {
+		return interval
+	}
+	return opts.ExpiryDuration
+}
+
+// 定時清理過期的 workers，Pool 被關閉後就會返回
 func (p *Pool) ClearStaleWorkers(ctx context.Context) {
-	ticker := time.NewTicker(p.options.ExpiryDuration)
+	ticker := time.NewTicker(p.cleanInterval())
 
 	defer func() {
 		ticker.Stop()
@@ -109,11 +321,20 @@ func (p *Pool) ClearStaleWorkers(ctx context.Context) {
 			return
 		case <-ticker.C:
 			if p.IsClosed() {
-				break
+				return
 			}
 
+			opts := p.currentOptions()
 			p.lock.Lock()
-			staleWorkers := p.workers.refresh(p.options.ExpiryDuration)
+			expiry, reclaim := opts.ExpiryDuration, 0
+			if p.adaptive != nil {
+				expiry, reclaim = p.adaptive.tick(p.workers.len())
+			}
+			// 限制每次清理的數量，避免一次 finish 大量 Worker 造成延遲
+			if max := opts.MaxCleanPerTick; max > 0 && (reclaim == 0 || reclaim > max) {
+				reclaim = max
+			}
+			staleWorkers := p.workers.refresh(expiry, opts.MinIdleWorkers, reclaim)
 			p.lock.Unlock()
 
 			for i := range staleWorkers {
@@ -126,16 +347,140 @@ func (p *Pool) ClearStaleWorkers(ctx context.Context) {
 
 // 獲取 worker 執行任務
 func (p *Pool) Schedule(task func()) error {
-	// 判斷Pool是否被關閉
+	// 判斷Pool是否還能接受新的任務
+	if err := p.checkAccepting(); err != nil {
+		return err
+	}
+	return p.schedule(task)
+}
+
+// 派發已經被接受的任務，排空中的 Pool 也會繼續派發，例如到期的延遲任務，沒有可用的 Worker 時交給 RejectionPolicy 處理
+func (p *Pool) schedule(task func()) error {
+	if err := p.submit(task, !p.currentOptions().Nonblocking, false); err != errNoWorker {
+		return err
+	}
+	return p.reject(task)
+}
+
+// 派發內部包裝的任務，例如 key 排隊、合併、tag 與子 Pool 的任務，這些任務必須執行才能釋放狀態，
+// 所以不交給 RejectionPolicy 處理，也不會被 DiscardOldestPolicy 丟棄，任務不會執行時一定返回錯誤
+func (p *Pool) dispatch(task func(), block bool) error {
+	if err := p.submit(task, block, true); err != errNoWorker {
+		return err
+	}
+	return ErrPoolOverload
+}
+
+// 派發已經被接受且不能等待的任務，例如到期的延遲任務，沒有可用的 Worker 時放進任務佇列等待，不受 TaskQueueSize 限制，
+// 只有 Pool 被關閉時才會返回 ErrPoolClosed
+func (p *Pool) handoff(task func()) error {
 	if p.IsClosed() {
 		return ErrPoolClosed
 	}
 
-	if w := p.getWorker(); w != nil {
+	if p.adaptive != nil {
+		p.adaptive.arrive()
+	}
+
+	w, queued := p.getWorker(task, false, true)
+	if w != nil {
 		w.inputFunc(task)
 		return nil
 	}
-	return ErrPoolOverload
+	if queued {
+		return nil
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.IsClosed() {
+		return ErrPoolClosed
+	}
+	p.tasks.forcePush(task, true)
+	return nil
+}
+
+// 將任務交給 Worker 或放進佇列，從 Worker 裡面提交的任務依照 NestedPolicy 處理，沒有可用的 Worker 時返回 errNoWorker
+func (p *Pool) submit(task func(), block, internal bool) error {
+	if p.IsClosed() {
+		return ErrPoolClosed
+	}
+
+	if p.adaptive != nil {
+		p.adaptive.arrive()
+	}
+
+	// 從 Worker 裡面提交的任務
+	if w := p.nestedWorker(); w != nil {
+		// 放進該 Worker 的 deque
+		if p.currentOptions().WorkStealing {
+			p.scheduleLocal(w, task)
+			return nil
+		}
+		return p.scheduleNested(task, internal)
+	}
+
+	w, queued := p.getWorker(task, block, internal)
+	if w != nil {
+		w.inputFunc(task)
+		return nil
+	}
+	if queued {
+		return nil
+	}
+	// 等待 Worker 時 Pool 被關閉
+	if p.IsClosed() {
+		return ErrPoolOverload
+	}
+	// 記憶體超過上限時不交給 RejectionPolicy 處理，避免 CallerRunsPolicy 繼續消耗記憶體
+	if p.memory != nil && p.memory.over() {
+		return ErrMemoryLimitExceeded
+	}
+	return errNoWorker
+}
+
+// 預先開啟 n 個閒置的 Worker，避免第一批任務需要等待 goroutine 啟動，超過 Pool 容量的部分會被忽略
+func (p *Pool) Warmup(n int) error {
+	if p.IsClosed() {
+		return ErrPoolClosed
+	}
+
+	for i := 0; i < n; i++ {
+		p.lock.Lock()
+		if cap := p.Cap(); p.IsClosed() || cap != -1 && cap <= p.Running() {
+			p.lock.Unlock()
+			break
+		}
+		w := p.workerCache.Get().(*Worker)
+		w.run()
+		w.lastUpdatedTime = time.Now()
+		err := p.workers.insert(w)
+		p.lock.Unlock()
+
+		if err != nil {
+			w.finish()
+			break
+		}
+		// 喚醒 Blocking 等待 worker 的 task
+		p.cond.Signal()
+	}
+	return nil
+}
+
+// 獲取目前有效的併發上限，為 Pool 容量與 ConcurrencyLimiter 上限的較小值，-1 代表沒有上限
+func (p *Pool) Limit() int {
+	p.lock.Lock()
+	limiter := p.limiter
+	p.lock.Unlock()
+
+	c := p.Cap()
+	if limiter == nil {
+		return c
+	}
+	if l := limiter.Limit(); c == -1 || l < c {
+		return l
+	}
+	return c
 }
 
 // 獲取 Pool 容量
@@ -143,6 +488,22 @@ func (p *Pool) Cap() int {
 	return int(atomic.LoadInt32(&p.capacity))
 }
 
+// 調整 Pool 容量，無限容量的 Pool 不能調整，
+// 縮小時多出來的 Worker 會在完成任務後退出，超過 Worker queue 大小的部分無法保持閒置
+func (p *Pool) Tune(size int) {
+	capacity := p.Cap()
+	if capacity == -1 || size <= 0 || size == capacity {
+		return
+	}
+	atomic.StoreInt32(&p.capacity, int32(size))
+	if size > capacity {
+		// 喚醒等待 Worker 的呼叫
+		p.cond.Broadcast()
+		p.wakeWeighted()
+		p.wakeTenants()
+	}
+}
+
 // Free returns the number of available goroutines to work, -1 indicates this pool is unlimited.
 func (p *Pool) Free() int {
 	c := p.Cap()
@@ -152,39 +513,337 @@ func (p *Pool) Free() int {
 	return c - p.Running()
 }
 
+// 獲取 Pool 目前使用的設定，包含補上的預設值，返回的是複本，修改不會影響 Pool
+func (p *Pool) Options() Options {
+	return *p.currentOptions().clone()
+}
+
+// 獲取目前的設定，返回的設定不能修改
+func (p *Pool) currentOptions() *Options {
+	return p.options.Load()
+}
+
+// 獲取目前清理 Worker 使用的過期時間，開啟 AdaptiveExpiry 時會隨著負載變動
+func (p *Pool) Expiry() time.Duration {
+	p.lock.Lock()
+	adaptive := p.adaptive
+	p.lock.Unlock()
+	expiry := p.currentOptions().ExpiryDuration
+
+	if adaptive != nil {
+		return adaptive.currentExpiry()
+	}
+	return expiry
+}
+
 // 獲取正在執行的 Worker 數量
 func (p *Pool) Running() int {
 	return int(atomic.LoadInt32(&p.running))
 }
 
+// 獲取阻塞等待 Worker 的呼叫數量
+func (p *Pool) Waiting() int {
+	return int(atomic.LoadInt32(&p.waiting))
+}
+
+// 獲取排隊等待 Worker 的任務數量
+func (p *Pool) Queued() int {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.tasks.len()
+}
+
 func (p *Pool) addRunning(delta int) {
 	atomic.AddInt32(&p.running, int32(delta))
 }
 
 // 清除 Pool 裡面的 Worker
 func (p *Pool) Release() {
-	if !atomic.CompareAndSwapInt32(&p.state, OPENED, CLOSED) {
+	p.lifecycle.Lock()
+	defer p.lifecycle.Unlock()
+
+	if !atomic.CompareAndSwapInt32(&p.state, OPENED, CLOSED) &&
+		!atomic.CompareAndSwapInt32(&p.state, DRAINING, CLOSED) {
 		return
 	}
+	atomic.AddUint32(&p.epoch, 1)
 
 	if p.stopClear != nil {
 		p.stopClear()
 		p.stopClear = nil
 	}
 
+	if p.stopAutoscale != nil {
+		p.stopAutoscale()
+		p.stopAutoscale = nil
+	}
+
+	// 丟棄尚未執行的延遲任務
+	p.timers.reset()
+
 	p.lock.Lock()
 	p.workers.reset()
+	// 丟棄排隊中的任務
+	p.tasks.reset()
+	p.resetWeighted()
+	p.tenants.reset()
 	p.lock.Unlock()
 
 	p.cond.Broadcast()
+	// 喚醒等待 tag 名額的任務
+	p.tagged.broadcast()
+	// 一起關閉子 Pool
+	p.children.release()
+	// 排隊中的任務已經被丟棄
+	p.notifyIdle()
+
+	go p.awaitStopped(p.done, p.clearStopped, p.autoscaleStopped)
+}
+
+// 等待背景的 goroutine 與所有 Worker 退出後關閉 done，期間被 Reboot 時不再等待 Worker
+func (p *Pool) awaitStopped(done chan struct{}, background ...chan struct{}) {
+	for _, stopped := range background {
+		if stopped != nil {
+			<-stopped
+		}
+	}
+
+	p.lock.Lock()
+	for p.IsClosed() && p.Running() > 0 {
+		p.cond.Wait()
+	}
+	p.lock.Unlock()
+	close(done)
 }
 
 // 重啟一個可以使用的 Pool
 func (p *Pool) Reboot() {
-	if atomic.CompareAndSwapInt32(&p.state, CLOSED, OPENED) {
-		atomic.StoreInt32(&p.clearDone, 0)
+	p.lifecycle.Lock()
+	defer p.lifecycle.Unlock()
+
+	if atomic.LoadInt32(&p.state) != CLOSED {
+		return
+	}
+	p.restart()
+}
+
+// 以新的設定重啟已經被 Release 的 Pool，沒有傳入的設定維持不變，檢查規則與 NewPool 相同，
+// 會等待上一次的 Worker 全部退出後才套用，避免執行中的任務讀到新的設定，任務一直沒有結束時會一直等待，
+// 需要限制等待時間時先以 Done() 搭配 timeout 等待 Pool 完全停止再呼叫，
+// 重啟期間不能同時提交任務，Options、Limit、Expiry、Queued 與 WaitIdle 可以同時呼叫
+func (p *Pool) RebootWithOptions(options ...Option) error {
+	done := p.Done()
+	if p.State() != CLOSED {
+		return ErrPoolNotClosed
+	}
+	<-done
+
+	p.lifecycle.Lock()
+	defer p.lifecycle.Unlock()
+
+	// 等待期間可能已經被其他呼叫 Reboot
+	if atomic.LoadInt32(&p.state) != CLOSED {
+		return ErrPoolNotClosed
+	}
+
+	opts := p.currentOptions().clone()
+	for _, option := range options {
+		option(opts)
+	}
+	size, err := validateOptions(p.Cap(), opts)
+	if err != nil {
+		return err
+	}
+
+	// 與 Limit、Expiry 等讀取狀態的呼叫互斥
+	p.lock.Lock()
+	p.options.Store(opts)
+	atomic.StoreInt32(&p.capacity, int32(size))
+	p.applyOptions()
+	p.lock.Unlock()
+	p.tagged.applyLimits(opts.TagLimits)
+	p.restart()
+	return nil
+}
+
+// 直接在執行中的 Pool 套用 LiveOptions，不需要 Release 或 Drain，已經提交的任務、延遲任務與週期任務都不受影響，
+// 其他設定維持不變，檢查規則與 NewPool 相同，Pool 已經關閉時返回 ErrPoolClosed
+func (p *Pool) Reconfigure(live LiveOptions) error {
+	p.lifecycle.Lock()
+	defer p.lifecycle.Unlock()
+
+	if p.IsClosed() {
+		return ErrPoolClosed
+	}
+
+	old := p.currentOptions()
+	opts := old.clone()
+	opts.ExpiryDuration = live.ExpiryDuration
+	opts.Nonblocking = live.Nonblocking
+	opts.DisableClear = live.DisableClear
+	opts.PreAlloc = live.PreAlloc
+	if _, err := validateOptions(p.Cap(), opts); err != nil {
+		return err
+	}
+
+	// 清理的頻率或是否清理改變時，先停止清理的 goroutine，避免它讀到新的設定
+	restartClear := opts.ExpiryDuration != old.ExpiryDuration || opts.DisableClear != old.DisableClear
+	if restartClear {
+		p.stopClearing()
+	}
+
+	p.lock.Lock()
+	p.options.Store(opts)
+	// 依照新的 PreAlloc 重建佇列，閒置的 Worker 依照原本的順序搬過去
+	if opts.PreAlloc != old.PreAlloc {
+		workers := p.newWorkerQueue(opts)
+		for w := p.workers.detach(); w != nil; w = p.workers.detach() {
+			if workers.insert(w) != nil {
+				w.finish()
+			}
+		}
+		p.workers = workers
+	}
+	p.lock.Unlock()
+
+	if restartClear {
 		p.goClear()
 	}
+	return nil
+}
+
+// 重新開啟 Pool 與背景的 goroutine，呼叫時必須持有 p.lifecycle
+func (p *Pool) restart() {
+	// 等待上一次的清理與自動調整的 goroutine 退出，避免與新的 goroutine 同時執行
+	for _, stopped := range []chan struct{}{p.clearStopped, p.autoscaleStopped} {
+		if stopped != nil {
+			<-stopped
+		}
+	}
+	p.clearStopped, p.autoscaleStopped = nil, nil
+
+	atomic.StoreInt32(&p.clearDone, 0)
+	p.done = make(chan struct{})
+	atomic.StoreInt32(&p.state, OPENED)
+
+	// 讓等待 Worker 退出的 awaitStopped 結束
+	p.lock.Lock()
+	p.cond.Broadcast()
+	p.lock.Unlock()
+
+	p.goClear()
+	p.goAutoscale()
+}
+
+// 開始排空 Pool: 新的任務會返回 ErrPoolDraining，已經提交、排隊中與阻塞等待的任務執行完後自動 Release，
+// 延遲任務與週期任務不再觸發，可以透過 Done() 等待 Pool 完全停止
+func (p *Pool) Drain() {
+	p.lifecycle.Lock()
+	if !atomic.CompareAndSwapInt32(&p.state, OPENED, DRAINING) {
+		p.lifecycle.Unlock()
+		return
+	}
+	atomic.AddUint32(&p.epoch, 1)
+	p.timers.reset()
+	p.lifecycle.Unlock()
+
+	p.checkDrained()
+}
+
+// 排空中的 Pool 已經沒有任何任務時就 Release
+func (p *Pool) checkDrained() {
+	if atomic.LoadInt32(&p.state) != DRAINING {
+		return
+	}
+
+	p.lock.Lock()
+	drained := atomic.LoadInt32(&p.inflight) == 0 && p.Waiting() == 0 && p.tasks.len() == 0 &&
+		p.tenants.waiting == 0 && len(p.weighted.waiters) == 0 && atomic.LoadInt32(&p.stealing.pending) == 0
+	p.lock.Unlock()
+
+	// 可能在 Worker 上呼叫，Release 會 finish 閒置的 Worker，需要在其他 goroutine 執行
+	if drained {
+		go p.Release()
+	}
+}
+
+// 阻塞等待的呼叫結束等待，例如拿到 Worker、交出任務或被 Release 喚醒，等待期間計入 Waiting，
+// 避免 Drain 在任務交出之前就關閉 Pool，結束時 Pool 可能已經排空或閒置，不能在持有 p.lock 時呼叫
+func (p *Pool) doneWaiting() {
+	atomic.AddInt32(&p.waiting, -1)
+	p.checkDrained()
+	p.notifyIdle()
+}
+
+// 返回 Pool 完全停止時會被關閉的 channel: Pool 已經關閉、背景的 goroutine 與所有 Worker 都已經退出，
+// 每次 Reboot 之後會換成新的 channel
+func (p *Pool) Done() <-chan struct{} {
+	p.lifecycle.Lock()
+	defer p.lifecycle.Unlock()
+	return p.done
+}
+
+// 獲取 Pool 狀態: OPENED、DRAINING 或 CLOSED
+func (p *Pool) State() int32 {
+	return atomic.LoadInt32(&p.state)
+}
+
+// 判斷是否還能接受新的任務
+func (p *Pool) checkAccepting() error {
+	switch atomic.LoadInt32(&p.state) {
+	case CLOSED:
+		return ErrPoolClosed
+	case DRAINING:
+		return ErrPoolDraining
+	}
+	return nil
+}
+
+func (p *Pool) addInflight(delta int) {
+	atomic.AddInt32(&p.inflight, int32(delta))
+}
+
+// 任務完成，最後一個任務完成時喚醒 WaitIdle
+func (p *Pool) finishInflight() {
+	if atomic.AddInt32(&p.inflight, -1) == 0 {
+		p.notifyIdle()
+	}
+}
+
+// 是否還能派發新的任務給 Worker
+func (p *Pool) admit() bool {
+	if p.Paused() {
+		return false
+	}
+	// 有加權任務在等待時，之後的任務也要排在後面
+	if len(p.weighted.waiters) > 0 {
+		return false
+	}
+	// 加權任務額外佔用的容量
+	if extra := p.weighted.extra; extra > 0 {
+		if capacity := p.Cap(); capacity != -1 && int(atomic.LoadInt32(&p.inflight))+extra >= capacity {
+			return false
+		}
+	}
+	if p.limiter != nil && int(atomic.LoadInt32(&p.inflight)) >= p.limiter.Limit() {
+		return false
+	}
+	return true
+}
+
+// 是否需要記錄任務的執行時間
+func (p *Pool) observesLatency() bool {
+	return p.autoscaler != nil || p.limiter != nil
+}
+
+// 記錄任務的執行時間，inflight 為任務完成前的 in-flight 數量
+func (p *Pool) observeLatency(rtt time.Duration, inflight int) {
+	if p.autoscaler != nil {
+		p.latency.observe(rtt)
+	}
+	if p.limiter != nil {
+		p.limiter.OnSample(rtt, inflight)
+	}
 }
 
 // 判斷是否被關閉
@@ -192,28 +851,57 @@ func (p *Pool) IsClosed() bool {
 	return atomic.LoadInt32(&p.state) == CLOSED
 }
 
-func (p *Pool) getWorker() (w worker) {
+// 獲取可用的 Worker，若 Pool 已滿但佇列還有空間，就把任務放進佇列並返回 queued = true，
+// block 為 false 時不會等待 Worker，internal 代表任務是內部包裝的任務
+func (p *Pool) getWorker(task func(), block, internal bool) (w worker, queued bool) {
+	// 記憶體超過上限時不派發新的任務
+	if p.memory != nil && !p.waitMemory(block) {
+		return
+	}
+
+	var spawn, waited bool
+	// 拿到 Worker 之後才不算在等待中，避免 WaitIdle 在中間誤判為閒置
+	defer func() {
+		if waited {
+			p.doneWaiting()
+		}
+	}()
+
 	// 加鎖
 	p.lock.Lock()
 retry:
 
-	if w = p.workers.detach(); w != nil {
+	if w, spawn = p.reserveWorker(); w != nil || spawn {
 		p.lock.Unlock()
+		// 當前無可用worker，但是Pool沒有滿
+		if spawn {
+			w = p.spawnWorker()
+		}
 		return
 	}
-	if cap := p.Cap(); cap == -1 || cap > p.Running() {
+	// 放進佇列，等 Worker 在 putWorker 時取出執行
+	if p.currentOptions().TaskQueueSize > 0 && p.tasks.push(task, internal) {
 		p.lock.Unlock()
-		// 當前無可用worker，但是Pool沒有滿
-		w = p.workerCache.Get().(*Worker)
-		w.run()
+		queued = true
+		return
+	}
+	// 暫停中的 Nonblocking Pool 不拒絕任務，放進佇列等 Resume 之後執行
+	if !block && p.Paused() && !p.IsClosed() {
+		p.tasks.forcePush(task, internal)
+		p.lock.Unlock()
+		queued = true
 		return
 	}
-	if p.options.Nonblocking {
+	if !block {
 		p.lock.Unlock()
 		return
 	}
 
 	// 阻塞等待
+	if !waited {
+		atomic.AddInt32(&p.waiting, 1)
+		waited = true
+	}
 	p.cond.Wait()
 
 	if p.IsClosed() {
@@ -224,13 +912,36 @@ retry:
 	goto retry
 }
 
-// 將 Worker 放回 Pool
-func (p *Pool) putWorker(worker *Worker) bool {
+// 取得閒置的 Worker，沒有閒置的 Worker 但 Pool 沒有滿時 spawn 為 true，兩者都會計入 in-flight，呼叫時必須持有 p.lock
+func (p *Pool) reserveWorker() (w worker, spawn bool) {
+	if !p.admit() {
+		return nil, false
+	}
+	if w = p.workers.detach(); w != nil {
+		p.addInflight(1)
+		return w, false
+	}
+	if cap := p.Cap(); cap == -1 || cap > p.Running() {
+		p.addInflight(1)
+		return nil, true
+	}
+	return nil, false
+}
+
+// 建立新的 Worker
+func (p *Pool) spawnWorker() worker {
+	w := p.workerCache.Get().(*Worker)
+	w.run()
+	return w
+}
+
+// 將 Worker 放回 Pool，若佇列中有排隊的任務，就直接返回給 Worker 執行
+func (p *Pool) putWorker(worker *Worker) (func(), bool) {
 	// 避免 Worker 超出 Pool 容量，或是 Pool 已關閉
 	capacity := p.Cap()
 	if capacity > 0 && p.Running() > capacity || p.IsClosed() {
 		p.cond.Broadcast()
-		return false
+		return nil, false
 	}
 
 	// 紀錄Woker最後一次運行時間
@@ -241,16 +952,76 @@ func (p *Pool) putWorker(worker *Worker) bool {
 	// 避免記憶體溢出
 	if p.IsClosed() {
 		p.lock.Unlock()
-		return false
+		return nil, false
+	}
+
+	// 在鎖內再檢查一次其他 Worker 的 deque，避免 scheduleLocal 找不到閒置的 Worker 而讓任務被遺漏
+	if p.currentOptions().WorkStealing && p.admit() {
+		if task := p.claimStolen(p.stealing.steal(worker)); task != nil {
+			p.lock.Unlock()
+			return task, true
+		}
+	}
+
+	// 優先執行容量足夠的加權任務
+	if granted := p.grantWeighted(); len(granted) > 0 {
+		p.lock.Unlock()
+		for _, waiter := range granted[1:] {
+			p.dispatchWeighted(waiter)
+		}
+		close(granted[0].ready)
+		return granted[0].task, true
+	}
+
+	// 以 deficit round robin 把 Worker 交給等待中的 tenant
+	if p.tenants.waiting > 0 && p.admit() {
+		waiter := p.tenants.pop()
+		p.addInflight(1)
+		p.lock.Unlock()
+		close(waiter.ready)
+		return waiter.task, true
+	}
+
+	// 優先執行排隊中的任務，佇列空出位置後喚醒 Blocking 等待的 task
+	if p.admit() {
+		if task := p.tasks.pop(); task != nil {
+			p.addInflight(1)
+			p.cond.Signal()
+			p.lock.Unlock()
+			return task, true
+		}
 	}
 
 	if err := p.workers.insert(worker); err != nil {
 		p.lock.Unlock()
-		return false
+		return nil, false
 	}
 
 	// 把 Blocking 等待 worker 的 task 喚醒
 	p.cond.Signal()
 	p.lock.Unlock()
-	return true
+
+	// 排空中的 Pool 在最後一個任務完成後關閉
+	p.checkDrained()
+	return nil, true
+}
+
+// Worker 退出後，若還有排隊中的任務且 Pool 沒有滿，就補上新的 Worker 執行
+func (p *Pool) dispatchQueued() {
+	p.lock.Lock()
+	if p.IsClosed() || p.tasks.len() == 0 || !p.admit() {
+		p.lock.Unlock()
+		return
+	}
+	if cap := p.Cap(); cap != -1 && cap <= p.Running() {
+		p.lock.Unlock()
+		return
+	}
+	task := p.tasks.pop()
+	p.addInflight(1)
+	p.lock.Unlock()
+
+	w := p.workerCache.Get().(*Worker)
+	w.run()
+	w.inputFunc(task)
 }
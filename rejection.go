@@ -0,0 +1,55 @@
+package grpool
+
+import "errors"
+
+// 沒有可用的 Worker，一般任務會交給 RejectionPolicy 處理
+var errNoWorker = errors.New("no worker available")
+
+// 任務被拒絕時的處理方式，返回的 error 會交給 Schedule 的呼叫者，
+// 當 Pool 滿載且沒有辦法等待或放進佇列時才會被呼叫，
+// ScheduleKeyed、ScheduleOnce、ScheduleTagged、ScheduleWeighted、ScheduleTenant 與子 Pool 的任務不會交給 RejectionPolicy，滿載時返回 ErrPoolOverload
+type RejectionPolicy func(task func(), p *Pool) error
+
+// 返回 ErrPoolOverload，為預設的處理方式
+func AbortPolicy(task func(), p *Pool) error {
+	return ErrPoolOverload
+}
+
+// 直接在呼叫 Schedule 的 goroutine 上執行任務
+func CallerRunsPolicy(task func(), p *Pool) error {
+	task()
+	return nil
+}
+
+// 直接丟棄任務
+func DiscardPolicy(task func(), p *Pool) error {
+	return nil
+}
+
+// 丟棄任務佇列中最早的任務，再把任務放進佇列，沒有設定 WithTaskQueue 時與 AbortPolicy 相同，
+// 內部包裝的任務不會被丟棄，佇列中都是這類任務時返回 ErrPoolOverload
+func DiscardOldestPolicy(task func(), p *Pool) error {
+	if p.currentOptions().TaskQueueSize <= 0 {
+		return ErrPoolOverload
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.IsClosed() {
+		return ErrPoolClosed
+	}
+	if p.tasks.isFull() && !p.tasks.discardOldest() {
+		return ErrPoolOverload
+	}
+	p.tasks.forcePush(task, false)
+	return nil
+}
+
+// 處理被拒絕的任務
+func (p *Pool) reject(task func()) error {
+	if policy := p.currentOptions().RejectionPolicy; policy != nil {
+		return policy(task, p)
+	}
+	return AbortPolicy(task, p)
+}
@@ -0,0 +1,164 @@
+package grpool
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// 佔滿 Pool 的所有 Worker，返回的 func 會釋放它們
+func occupyPool(p *Pool, workers int) func() {
+	block := make(chan struct{})
+	var started sync.WaitGroup
+	started.Add(workers)
+	for i := 0; i < workers; i++ {
+		_ = p.Schedule(func() {
+			started.Done()
+			<-block
+		})
+	}
+	started.Wait()
+	return func() { close(block) }
+}
+
+func TestRejectionAbortPolicy(t *testing.T) {
+	p, _ := NewPool(2, WithNonblocking(true), WithRejectionPolicy(AbortPolicy))
+	defer p.Release()
+	defer occupyPool(p, 2)()
+
+	assert.ErrorIs(t, p.Schedule(demoFunc), ErrPoolOverload)
+}
+
+func TestRejectionCallerRunsPolicy(t *testing.T) {
+	p, _ := NewPool(2, WithNonblocking(true), WithRejectionPolicy(CallerRunsPolicy))
+	defer p.Release()
+	defer occupyPool(p, 2)()
+
+	id := goid()
+	var ran uint64
+	assert.NoError(t, p.Schedule(func() { ran = goid() }))
+	assert.Equal(t, id, ran, "task should run on the caller goroutine")
+}
+
+func TestRejectionDiscardPolicy(t *testing.T) {
+	p, _ := NewPool(2, WithNonblocking(true), WithRejectionPolicy(DiscardPolicy))
+	defer p.Release()
+	release := occupyPool(p, 2)
+
+	ran := make(chan struct{}, 1)
+	assert.NoError(t, p.Schedule(func() { ran <- struct{}{} }))
+	release()
+
+	select {
+	case <-ran:
+		t.Fatal("discarded task should not run")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestRejectionDiscardOldestPolicy(t *testing.T) {
+	p, _ := NewPool(1, WithNonblocking(true), WithTaskQueue(2), WithRejectionPolicy(DiscardOldestPolicy))
+	defer p.Release()
+	release := occupyPool(p, 1)
+
+	var (
+		lock   sync.Mutex
+		result []int
+		wg     sync.WaitGroup
+	)
+	for i := 0; i < 4; i++ {
+		i := i
+		if i >= 2 {
+			wg.Add(1)
+		}
+		assert.NoError(t, p.Schedule(func() {
+			lock.Lock()
+			result = append(result, i)
+			lock.Unlock()
+			wg.Done()
+		}))
+	}
+	assert.EqualValues(t, 2, p.Queued())
+	release()
+	wg.Wait()
+	assert.EqualValues(t, []int{2, 3}, result, "oldest queued tasks should be discarded")
+
+	// 沒有任務佇列時與 AbortPolicy 相同
+	p2, _ := NewPool(1, WithNonblocking(true), WithRejectionPolicy(DiscardOldestPolicy))
+	defer p2.Release()
+	defer occupyPool(p2, 1)()
+	assert.ErrorIs(t, p2.Schedule(demoFunc), ErrPoolOverload)
+}
+
+func TestRejectionHandler(t *testing.T) {
+	var rejected []func()
+	var pool *Pool
+	p, _ := NewPool(1, WithNonblocking(true), WithRejectionHandler(func(task func(), p *Pool) {
+		rejected = append(rejected, task)
+		pool = p
+	}))
+	defer p.Release()
+	defer occupyPool(p, 1)()
+
+	assert.NoError(t, p.Schedule(demoFunc))
+	assert.Len(t, rejected, 1)
+	assert.Equal(t, p, pool)
+}
+
+func TestRejectionPolicySkipsInternalTasks(t *testing.T) {
+	p, _ := NewPool(1, WithNonblocking(true), WithRejectionPolicy(DiscardPolicy))
+	defer p.Release()
+	release := occupyPool(p, 1)
+
+	// 內部包裝的任務不會被丟棄後當成已經派發
+	done := make(chan error, 1)
+	go func() {
+		_, err, _ := p.ScheduleOnce("once", func() (interface{}, error) { return nil, nil })
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		assert.ErrorIs(t, err, ErrPoolOverload)
+	case <-time.After(time.Second):
+		t.Fatal("ScheduleOnce should not hang when the pool is full")
+	}
+
+	assert.ErrorIs(t, p.ScheduleKeyed("key", demoFunc), ErrPoolOverload)
+	assert.ErrorIs(t, p.ScheduleTagged("tag", demoFunc), ErrPoolOverload)
+	assert.Equal(t, 0, p.TagStats("tag").Running, "tag slot should be released")
+
+	child, _ := p.NewChild(1)
+	assert.ErrorIs(t, child.Schedule(demoFunc), ErrPoolOverload)
+	assert.Equal(t, 0, child.Running(), "child slot should be released")
+	release()
+
+	// key 沒有卡住
+	ran := make(chan struct{})
+	assert.Eventually(t, func() bool { return p.ScheduleKeyed("key", func() { close(ran) }) == nil }, time.Second, 5*time.Millisecond)
+	select {
+	case <-ran:
+	case <-time.After(time.Second):
+		t.Fatal("keyed task should run after the rejected one")
+	}
+}
+
+func TestRejectionDiscardOldestKeepsInternalTasks(t *testing.T) {
+	p, _ := NewPool(1, WithNonblocking(true), WithTaskQueue(1), WithRejectionPolicy(DiscardOldestPolicy))
+	defer p.Release()
+	release := occupyPool(p, 1)
+
+	ran := make(chan struct{})
+	assert.NoError(t, p.ScheduleKeyed("key", func() { close(ran) }))
+	assert.EqualValues(t, 1, p.Queued())
+
+	// 佇列中只有內部包裝的任務時不能丟棄
+	assert.ErrorIs(t, p.Schedule(demoFunc), ErrPoolOverload)
+	release()
+	select {
+	case <-ran:
+	case <-time.After(time.Second):
+		t.Fatal("queued keyed task should not be discarded")
+	}
+}
@@ -0,0 +1,154 @@
+package grpool
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// 單一 tag 的統計資料
+type TagStats struct {
+	// 提交的任務數量
+	Submitted uint64
+
+	// 正在執行的任務數量，包含已經交給 Pool 但還在等待 Worker 的任務
+	Running int
+
+	// 被拒絕的任務數量
+	Rejected uint64
+}
+
+// 單一 tag 的狀態
+type tagState struct {
+	stats TagStats
+
+	// 同時執行的上限，0 代表只受 Pool 容量限制
+	limit int
+
+	// 達到上限時等待其他任務完成
+	cond *sync.Cond
+}
+
+// 依照 tag 分組的任務，所有 tag 共用同一個 Pool 的容量
+type tagGroup struct {
+	lock sync.Mutex
+	tags map[string]*tagState
+}
+
+// 獲取 tag 的狀態，不存在就建立，呼叫時必須持有 tg.lock
+func (tg *tagGroup) get(tag string, limits map[string]int) *tagState {
+	if tg.tags == nil {
+		tg.tags = make(map[string]*tagState)
+	}
+	ts, ok := tg.tags[tag]
+	if !ok {
+		ts = &tagState{limit: limits[tag], cond: sync.NewCond(&tg.lock)}
+		tg.tags[tag] = ts
+	}
+	return ts
+}
+
+// 把 RebootWithOptions 的 TagLimits 套用到已經建立的 tag，沒有設定的 tag 維持目前的上限
+func (tg *tagGroup) applyLimits(limits map[string]int) {
+	tg.lock.Lock()
+	for tag, limit := range limits {
+		if ts, ok := tg.tags[tag]; ok {
+			ts.limit = limit
+			ts.cond.Broadcast()
+		}
+	}
+	tg.lock.Unlock()
+}
+
+// 喚醒所有等待中的任務，Pool 被關閉時使用
+func (tg *tagGroup) broadcast() {
+	tg.lock.Lock()
+	for _, ts := range tg.tags {
+		ts.cond.Broadcast()
+	}
+	tg.lock.Unlock()
+}
+
+// 以 tag 分組執行任務，同一個 tag 同時執行的任務數量不會超過 WithTagLimit 設定的上限，
+// 達到上限時 Blocking 模式會等待同一個 tag 的任務完成，Nonblocking 模式則返回 ErrTagOverload，
+// Pool 滿載時不交給 RejectionPolicy 處理，而是返回 ErrPoolOverload 並釋放名額
+func (p *Pool) ScheduleTagged(tag string, task func()) error {
+	if task == nil {
+		return ErrLackPoolFunc
+	}
+	if err := p.checkAccepting(); err != nil {
+		return err
+	}
+
+	limits := p.currentOptions().TagLimits
+	tg := &p.tagged
+	tg.lock.Lock()
+	ts := tg.get(tag, limits)
+	ts.stats.Submitted++
+	waited := false
+	for ts.limit > 0 && ts.stats.Running >= ts.limit {
+		if p.currentOptions().Nonblocking {
+			ts.stats.Rejected++
+			tg.lock.Unlock()
+			return ErrTagOverload
+		}
+		// 等到任務交給 Pool 之後才不算在等待中
+		if !waited {
+			atomic.AddInt32(&p.waiting, 1)
+			waited = true
+		}
+		ts.cond.Wait()
+		if p.IsClosed() {
+			tg.lock.Unlock()
+			p.doneWaiting()
+			return ErrPoolClosed
+		}
+	}
+	ts.stats.Running++
+	tg.lock.Unlock()
+
+	err := p.dispatch(func() {
+		defer p.doneTagged(ts, false)
+		task()
+	}, !p.currentOptions().Nonblocking)
+	if err != nil {
+		p.doneTagged(ts, true)
+	}
+	if waited {
+		p.doneWaiting()
+	}
+	return err
+}
+
+// 任務完成或被 Pool 拒絕後釋放名額，並喚醒同一個 tag 等待中的任務
+func (p *Pool) doneTagged(ts *tagState, rejected bool) {
+	p.tagged.lock.Lock()
+	ts.stats.Running--
+	if rejected {
+		ts.stats.Rejected++
+	}
+	ts.cond.Signal()
+	p.tagged.lock.Unlock()
+}
+
+// 獲取 tag 的統計資料
+func (p *Pool) TagStats(tag string) TagStats {
+	p.tagged.lock.Lock()
+	defer p.tagged.lock.Unlock()
+	if ts, ok := p.tagged.tags[tag]; ok {
+		return ts.stats
+	}
+	return TagStats{}
+}
+
+// 調整 tag 同時執行的上限，0 代表只受 Pool 容量限制
+func (p *Pool) SetTagLimit(tag string, limit int) {
+	if limit < 0 {
+		return
+	}
+	limits := p.currentOptions().TagLimits
+	p.tagged.lock.Lock()
+	ts := p.tagged.get(tag, limits)
+	ts.limit = limit
+	ts.cond.Broadcast()
+	p.tagged.lock.Unlock()
+}
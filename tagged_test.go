@@ -0,0 +1,138 @@
+package grpool
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScheduleTaggedLimit(t *testing.T) {
+	p, _ := NewPool(10, WithTagLimit("a", 2))
+	defer p.Release()
+
+	var (
+		running, max int32
+		wg           sync.WaitGroup
+	)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			assert.NoError(t, p.ScheduleTagged("a", func() {
+				defer wg.Done()
+				n := atomic.AddInt32(&running, 1)
+				for {
+					m := atomic.LoadInt32(&max)
+					if n <= m || atomic.CompareAndSwapInt32(&max, m, n) {
+						break
+					}
+				}
+				time.Sleep(10 * time.Millisecond)
+				atomic.AddInt32(&running, -1)
+			}))
+		}()
+	}
+	wg.Wait()
+
+	assert.LessOrEqual(t, atomic.LoadInt32(&max), int32(2))
+	time.Sleep(10 * time.Millisecond)
+	assert.Equal(t, TagStats{Submitted: 10}, p.TagStats("a"))
+}
+
+func TestScheduleTaggedBulkhead(t *testing.T) {
+	p, _ := NewPool(4, WithNonblocking(true), WithTagLimit("bulk", 2))
+	defer p.Release()
+
+	block := make(chan struct{})
+	for i := 0; i < 2; i++ {
+		assert.NoError(t, p.ScheduleTagged("bulk", func() { <-block }))
+	}
+	assert.ErrorIs(t, p.ScheduleTagged("bulk", demoFunc), ErrTagOverload)
+	assert.Equal(t, TagStats{Submitted: 3, Running: 2, Rejected: 1}, p.TagStats("bulk"))
+
+	// 其他 tag 不受影響，但仍然共用 Pool 的容量
+	for i := 0; i < 2; i++ {
+		assert.NoError(t, p.ScheduleTagged("other", func() { <-block }))
+	}
+	assert.ErrorIs(t, p.ScheduleTagged("other", demoFunc), ErrPoolOverload)
+	assert.Equal(t, TagStats{Submitted: 3, Running: 2, Rejected: 1}, p.TagStats("other"))
+	close(block)
+}
+
+func TestSetTagLimit(t *testing.T) {
+	_, err := NewPool(4, WithTagLimit("a", -1))
+	assert.ErrorIs(t, err, ErrInvalidTagLimit)
+
+	p, _ := NewPool(4, WithTagLimit("a", 1))
+	defer p.Release()
+
+	block := make(chan struct{})
+	defer close(block)
+	assert.NoError(t, p.ScheduleTagged("a", func() { <-block }))
+
+	started := make(chan struct{})
+	go func() {
+		_ = p.ScheduleTagged("a", func() { close(started) })
+	}()
+	select {
+	case <-started:
+		t.Fatal("task should wait for the tag limit")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	// 提高上限後喚醒等待中的任務
+	p.SetTagLimit("a", 2)
+	select {
+	case <-started:
+	case <-time.After(time.Second):
+		t.Fatal("task should run after raising the tag limit")
+	}
+}
+
+func TestScheduleTaggedDrain(t *testing.T) {
+	p, _ := NewPool(4, WithTagLimit("a", 1))
+	block := make(chan struct{})
+	assert.NoError(t, p.ScheduleTagged("a", func() { <-block }))
+
+	// 等待名額的呼叫計入 Waiting，Drain 會等它交出任務
+	var ran int32
+	blocked := make(chan error, 1)
+	go func() {
+		blocked <- p.ScheduleTagged("a", func() { atomic.AddInt32(&ran, 1) })
+	}()
+	assert.Eventually(t, func() bool { return p.Waiting() == 1 }, time.Second, time.Millisecond)
+
+	p.Drain()
+	close(block)
+	assert.NoError(t, <-blocked)
+	select {
+	case <-p.Done():
+	case <-time.After(time.Second):
+		t.Fatal("pool should stop after draining")
+	}
+	assert.EqualValues(t, 1, atomic.LoadInt32(&ran))
+}
+
+func TestScheduleTaggedRebootWithOptions(t *testing.T) {
+	p, _ := NewPool(4, WithNonblocking(true), WithTagLimit("a", 1))
+	defer p.Release()
+
+	block := make(chan struct{})
+	assert.NoError(t, p.ScheduleTagged("a", func() { <-block }))
+	assert.ErrorIs(t, p.ScheduleTagged("a", demoFunc), ErrTagOverload)
+	close(block)
+	p.Release()
+	<-p.Done()
+
+	// 已經建立的 tag 也要套用新的上限
+	assert.NoError(t, p.RebootWithOptions(WithTagLimit("a", 3)))
+	assert.Equal(t, 3, p.Options().TagLimits["a"])
+	block = make(chan struct{})
+	defer close(block)
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, p.ScheduleTagged("a", func() { <-block }))
+	}
+	assert.ErrorIs(t, p.ScheduleTagged("a", demoFunc), ErrTagOverload)
+}
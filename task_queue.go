@@ -0,0 +1,104 @@
+package grpool
+
+// 排隊中的任務
+type queuedTask struct {
+	task func()
+
+	// 內部包裝的任務，例如 key 排隊或合併的任務，不能被 DiscardOldestPolicy 丟棄
+	internal bool
+}
+
+// 等待 Worker 的任務佇列，使用可以擴充的環狀陣列
+type taskQueue struct {
+	items []queuedTask
+	head  int
+	count int
+
+	// 佇列上限，0 代表沒有上限
+	limit int
+}
+
+// 初始化 taskQueue
+func newTaskQueue(limit int) *taskQueue {
+	return &taskQueue{limit: limit}
+}
+
+// 獲取排隊中的任務數量
+func (q *taskQueue) len() int {
+	return q.count
+}
+
+// 判斷佇列是否已滿
+func (q *taskQueue) isFull() bool {
+	return q.limit > 0 && q.count >= q.limit
+}
+
+// 加入任務，佇列已滿就返回 false
+func (q *taskQueue) push(task func(), internal bool) bool {
+	if q.isFull() {
+		return false
+	}
+	q.forcePush(task, internal)
+	return true
+}
+
+// 不論上限都加入任務，用於不能被拒絕的任務
+func (q *taskQueue) forcePush(task func(), internal bool) {
+	if q.count == len(q.items) {
+		q.grow()
+	}
+	q.items[(q.head+q.count)%len(q.items)] = queuedTask{task: task, internal: internal}
+	q.count++
+}
+
+// 取出最早加入的任務，沒有任務就返回 nil
+func (q *taskQueue) pop() func() {
+	if q.count == 0 {
+		return nil
+	}
+	task := q.items[q.head].task
+	q.items[q.head] = queuedTask{} // 避免記憶體溢出
+	q.head = (q.head + 1) % len(q.items)
+	q.count--
+	return task
+}
+
+// 丟棄最早加入且不是內部包裝的任務，後面的任務依序往前移，沒有可以丟棄的任務就返回 false
+func (q *taskQueue) discardOldest() bool {
+	for i := 0; i < q.count; i++ {
+		if q.items[(q.head+i)%len(q.items)].internal {
+			continue
+		}
+		for ; i+1 < q.count; i++ {
+			q.items[(q.head+i)%len(q.items)] = q.items[(q.head+i+1)%len(q.items)]
+		}
+		q.items[(q.head+q.count-1)%len(q.items)] = queuedTask{}
+		q.count--
+		return true
+	}
+	return false
+}
+
+// 空間不足時擴充為兩倍，並把任務依序搬到開頭
+func (q *taskQueue) grow() {
+	n := len(q.items) * 2
+	if n == 0 {
+		n = 8
+	}
+	if q.limit > 0 && n > q.limit && q.count < q.limit {
+		n = q.limit
+	}
+	items := make([]queuedTask, n)
+	for i := 0; i < q.count; i++ {
+		items[i] = q.items[(q.head+i)%len(q.items)]
+	}
+	q.items = items
+	q.head = 0
+}
+
+// 丟棄所有排隊中的任務
+func (q *taskQueue) reset() {
+	q.items = nil
+	q.head = 0
+	q.count = 0
+}
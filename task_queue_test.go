@@ -0,0 +1,131 @@
+package grpool
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTaskQueue(t *testing.T) {
+	q := newTaskQueue(20)
+	assert.EqualValues(t, 0, q.len(), "Len error")
+	assert.Nil(t, q.pop(), "Pop error")
+
+	var pushed, result []int
+	push := func() bool {
+		id := len(pushed)
+		if !q.push(func() { result = append(result, id) }, false) {
+			return false
+		}
+		pushed = append(pushed, id)
+		return true
+	}
+
+	// 多次擴充與繞回
+	for round := 0; round < 3; round++ {
+		for i := 0; i < 10; i++ {
+			assert.True(t, push(), "Push error")
+		}
+		for i := 0; i < 7; i++ {
+			q.pop()()
+		}
+	}
+	assert.EqualValues(t, 9, q.len(), "Len error")
+	for i := 0; i < 11; i++ {
+		assert.True(t, push(), "Push error")
+	}
+	assert.False(t, push(), "queue should be full")
+	assert.LessOrEqual(t, len(q.items), 20, "queue should not grow beyond limit")
+
+	// 依照加入順序取出
+	for task := q.pop(); task != nil; task = q.pop() {
+		task()
+	}
+	assert.EqualValues(t, pushed, result)
+
+	q.reset()
+	assert.EqualValues(t, 0, q.len(), "Len error")
+}
+
+func TestGrPoolWithTaskQueue(t *testing.T) {
+	p, _ := NewPool(2, WithNonblocking(true), WithTaskQueue(10))
+	defer p.Release()
+
+	var (
+		wg    sync.WaitGroup
+		count int32
+		block = make(chan struct{})
+	)
+	for i := 0; i < 12; i++ {
+		wg.Add(1)
+		err := p.Schedule(func() {
+			<-block
+			atomic.AddInt32(&count, 1)
+			wg.Done()
+		})
+		assert.NoError(t, err)
+	}
+	assert.EqualValues(t, 2, p.Running())
+	assert.EqualValues(t, 10, p.Queued())
+	assert.ErrorIs(t, p.Schedule(demoFunc), ErrPoolOverload, "should overload when the queue is full")
+
+	close(block)
+	wg.Wait()
+	assert.EqualValues(t, 12, atomic.LoadInt32(&count))
+	assert.EqualValues(t, 0, p.Queued())
+	assert.LessOrEqual(t, p.Running(), 2)
+
+	_, err := NewPool(2, WithTaskQueue(-1))
+	assert.ErrorIs(t, err, ErrInvalidTaskQueueSize)
+}
+
+func TestGrPoolWithTaskQueueBlocking(t *testing.T) {
+	p, _ := NewPool(1, WithTaskQueue(1))
+	defer p.Release()
+
+	block := make(chan struct{})
+	_ = p.Schedule(func() { <-block })
+	_ = p.Schedule(func() {})
+
+	// 佇列已滿，Blocking 模式會等待佇列空出位置
+	done := make(chan struct{})
+	go func() {
+		_ = p.Schedule(func() {})
+		close(done)
+	}()
+	select {
+	case <-done:
+		t.Fatal("Schedule should block when the queue is full")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(block)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Schedule should return after the queue is drained")
+	}
+}
+
+func TestGrPoolWithTaskQueuePanic(t *testing.T) {
+	p, _ := NewPool(1, WithNonblocking(true), WithTaskQueue(10), WithPanicHandler(func(interface{}) {}))
+	defer p.Release()
+
+	done := make(chan struct{})
+	block := make(chan struct{})
+	_ = p.Schedule(func() {
+		<-block
+		panic("error")
+	})
+	_ = p.Schedule(func() { close(done) })
+	close(block)
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("queued task should run after the worker panicked")
+	}
+}
@@ -0,0 +1,188 @@
+package grpool
+
+import "sync/atomic"
+
+// 等待 Worker 的 tenant 任務
+type tenantWaiter struct {
+	task func()
+
+	// 任務被派發或 Pool 被關閉時關閉
+	ready chan struct{}
+	err   error
+}
+
+// 單一 tenant 等待中的任務
+type tenantQueue struct {
+	name   string
+	weight int
+
+	// 這一輪還能派發的任務數量
+	deficit int
+
+	waiters []*tenantWaiter
+}
+
+// 以 deficit round robin 在 tenant 之間分配空出來的 Worker，每一輪每個 tenant 最多派發 weight 個任務，
+// 所有欄位都由 p.lock 保護
+type tenantScheduler struct {
+	tenants map[string]*tenantQueue
+
+	// 有任務在等待的 tenant，依照輪詢順序
+	active []*tenantQueue
+
+	// 等待中的任務數量
+	waiting int
+}
+
+// 加入等待中的任務，沒有任務在等待的 tenant 會排在這一輪的最後面
+func (ts *tenantScheduler) push(name string, weight int, waiter *tenantWaiter) {
+	if ts.tenants == nil {
+		ts.tenants = make(map[string]*tenantQueue)
+	}
+	q, ok := ts.tenants[name]
+	if !ok {
+		q = &tenantQueue{name: name}
+		ts.tenants[name] = q
+	}
+	q.weight = weight
+	if len(q.waiters) == 0 {
+		ts.active = append(ts.active, q)
+	}
+	q.waiters = append(q.waiters, waiter)
+	ts.waiting++
+}
+
+// 依照 deficit round robin 取出下一個任務
+func (ts *tenantScheduler) pop() *tenantWaiter {
+	if len(ts.active) == 0 {
+		return nil
+	}
+
+	q := ts.active[0]
+	// 輪到這個 tenant 時補上這一輪的額度
+	if q.deficit <= 0 {
+		q.deficit += q.weight
+	}
+	waiter := q.waiters[0]
+	q.waiters[0] = nil // 避免記憶體溢出
+	q.waiters = q.waiters[1:]
+	q.deficit--
+	ts.waiting--
+
+	switch {
+	case len(q.waiters) == 0:
+		// 沒有任務在等待時不保留額度，避免之後一次派發太多
+		q.deficit = 0
+		ts.active[0] = nil
+		ts.active = ts.active[1:]
+		delete(ts.tenants, q.name)
+	case q.deficit <= 0:
+		// 額度用完，換下一個 tenant
+		ts.active = append(ts.active[1:], q)
+	}
+	return waiter
+}
+
+// 讓所有等待中的任務返回 ErrPoolClosed
+func (ts *tenantScheduler) reset() {
+	for _, q := range ts.active {
+		for _, waiter := range q.waiters {
+			waiter.err = ErrPoolClosed
+			close(waiter.ready)
+		}
+	}
+	ts.tenants = nil
+	ts.active = nil
+	ts.waiting = 0
+}
+
+// 以 tenant 提交任務，Pool 滿載時 Blocking 模式的呼叫會依照 WithTenantWeight 設定的權重，
+// 以 deficit round robin 分配空出來的 Worker，避免單一 tenant 佔滿整個 Pool，沒有設定的 tenant 權重為 1，
+// Nonblocking 模式滿載時返回 ErrPoolOverload，不交給 RejectionPolicy 處理，
+// 在任務裡面提交時不會等待: NestedCallerRuns 或 WorkStealing 直接在提交的 Worker 上執行，NestedQueue 排隊後直接返回，
+// 暫停中的 Nonblocking Pool 也是排隊後直接返回，Resume 之後執行
+func (p *Pool) ScheduleTenant(tenant string, task func()) error {
+	if task == nil {
+		return ErrLackPoolFunc
+	}
+	if err := p.checkAccepting(); err != nil {
+		return err
+	}
+	nested := p.nestedWorker() != nil
+	if p.memory != nil && !p.waitMemory(!p.currentOptions().Nonblocking && !nested) {
+		if p.IsClosed() {
+			return ErrPoolClosed
+		}
+		return ErrMemoryLimitExceeded
+	}
+
+	p.lock.Lock()
+	// 在鎖內檢查，避免 Release 之後才加入等待
+	if err := p.checkAccepting(); err != nil {
+		p.lock.Unlock()
+		return err
+	}
+	// 已經有 tenant 在等待時直接排隊，避免插隊
+	if p.tenants.waiting == 0 {
+		if w, spawn := p.reserveWorker(); w != nil || spawn {
+			p.lock.Unlock()
+			if spawn {
+				w = p.spawnWorker()
+			}
+			w.inputFunc(task)
+			return nil
+		}
+	}
+	// 提交的 Worker 等待時，所有 Worker 都可能在等待而死鎖
+	if nested && p.nestedCallerRuns() {
+		p.lock.Unlock()
+		task()
+		return nil
+	}
+	// 與加權任務相同，不交給 RejectionPolicy 處理，避免繞過 tenant 之間的分配
+	if p.currentOptions().Nonblocking && !nested && !p.Paused() {
+		p.lock.Unlock()
+		return ErrPoolOverload
+	}
+
+	weight, ok := p.currentOptions().TenantWeights[tenant]
+	if !ok {
+		weight = 1
+	}
+	waiter := &tenantWaiter{task: task, ready: make(chan struct{})}
+	p.tenants.push(tenant, weight, waiter)
+	p.lock.Unlock()
+	// 巢狀提交或暫停中的 Nonblocking Pool 排隊後直接返回，不等待派發
+	if nested || p.currentOptions().Nonblocking {
+		return nil
+	}
+
+	atomic.AddInt32(&p.waiting, 1)
+	<-waiter.ready
+	p.doneWaiting()
+	return waiter.err
+}
+
+// 容量變大或 Worker 退出後，把等待中的 tenant 任務交給閒置的 Worker 或新的 Worker
+func (p *Pool) wakeTenants() {
+	for {
+		p.lock.Lock()
+		if p.tenants.waiting == 0 || p.IsClosed() {
+			p.lock.Unlock()
+			return
+		}
+		w, spawn := p.reserveWorker()
+		if w == nil && !spawn {
+			p.lock.Unlock()
+			return
+		}
+		waiter := p.tenants.pop()
+		p.lock.Unlock()
+
+		if spawn {
+			w = p.spawnWorker()
+		}
+		w.inputFunc(waiter.task)
+		close(waiter.ready)
+	}
+}
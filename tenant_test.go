@@ -0,0 +1,141 @@
+package grpool
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTenantScheduler(t *testing.T) {
+	var ts tenantScheduler
+	names := make(map[*tenantWaiter]string)
+	for i := 0; i < 10; i++ {
+		for _, name := range []string{"a", "b"} {
+			weight := 1
+			if name == "a" {
+				weight = 3
+			}
+			waiter := &tenantWaiter{}
+			names[waiter] = name
+			ts.push(name, weight, waiter)
+		}
+	}
+
+	var order []string
+	for i := 0; i < 8; i++ {
+		order = append(order, names[ts.pop()])
+	}
+	assert.Equal(t, []string{"a", "a", "a", "b", "a", "a", "a", "b"}, order)
+	assert.Equal(t, 12, ts.waiting)
+}
+
+// 模擬多個 tenant 同時塞滿 Pool，記錄任務實際執行的順序
+func simulateTenants(t *testing.T, p *Pool, tasks map[string]int) []string {
+	release := occupyPool(p, p.Cap())
+
+	var (
+		lock  sync.Mutex
+		order []string
+		wg    sync.WaitGroup
+		total int
+	)
+	for tenant, n := range tasks {
+		total += n
+		for i := 0; i < n; i++ {
+			wg.Add(1)
+			tenant := tenant
+			go func() {
+				defer wg.Done()
+				assert.NoError(t, p.ScheduleTenant(tenant, func() {
+					lock.Lock()
+					order = append(order, tenant)
+					lock.Unlock()
+				}))
+			}()
+		}
+	}
+	for p.Waiting() != total {
+		time.Sleep(time.Millisecond)
+	}
+
+	release()
+	wg.Wait()
+	for {
+		lock.Lock()
+		n := len(order)
+		lock.Unlock()
+		if n == total {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	return order
+}
+
+func countTenant(order []string, tenant string) int {
+	n := 0
+	for _, o := range order {
+		if o == tenant {
+			n++
+		}
+	}
+	return n
+}
+
+func TestScheduleTenantProportionalShares(t *testing.T) {
+	p, _ := NewPool(1, WithTenantWeight("gold", 3), WithTenantWeight("bronze", 1))
+	defer p.Release()
+
+	order := simulateTenants(t, p, map[string]int{"gold": 200, "bronze": 200})
+
+	// 兩個 tenant 都還有任務在等待時，空出來的 Worker 依照 3:1 分配
+	assert.InDelta(t, 150, countTenant(order[:200], "gold"), 3)
+	assert.InDelta(t, 50, countTenant(order[:200], "bronze"), 3)
+}
+
+func TestScheduleTenantNoisyNeighbor(t *testing.T) {
+	p, _ := NewPool(2)
+	defer p.Release()
+
+	order := simulateTenants(t, p, map[string]int{"noisy": 200, "quiet": 10})
+
+	// 權重相同時，quiet 不需要等 noisy 全部執行完
+	assert.Equal(t, 10, countTenant(order[:30], "quiet"))
+}
+
+func TestScheduleTenantRelease(t *testing.T) {
+	_, err := NewPool(1, WithTenantWeight("a", 0))
+	assert.ErrorIs(t, err, ErrInvalidTenantWeight)
+
+	p, _ := NewPool(1)
+	release := occupyPool(p, 1)
+	defer release()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- p.ScheduleTenant("a", demoFunc)
+	}()
+	for p.Waiting() != 1 {
+		time.Sleep(time.Millisecond)
+	}
+
+	p.Release()
+	assert.ErrorIs(t, <-done, ErrPoolClosed)
+}
+
+func TestScheduleTenantNonblocking(t *testing.T) {
+	var rejected int32
+	p, _ := NewPool(1, WithNonblocking(true), WithRejectionHandler(func(func(), *Pool) {
+		atomic.AddInt32(&rejected, 1)
+	}))
+	defer p.Release()
+	release := occupyPool(p, 1)
+	defer release()
+
+	// 不交給 RejectionPolicy 處理
+	assert.ErrorIs(t, p.ScheduleTenant("a", demoFunc), ErrPoolOverload)
+	assert.EqualValues(t, 0, atomic.LoadInt32(&rejected))
+}
@@ -0,0 +1,219 @@
+package grpool
+
+import (
+	"container/heap"
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// 延遲任務的 Handle，可以用來取消尚未執行的任務
+type Timer struct {
+	// 預計執行時間
+	when time.Time
+
+	// 任務
+	task func()
+
+	// 到期時在派發的 goroutine 上呼叫，返回 false 就不派發任務，週期任務用來預約下一次觸發
+	due func() bool
+
+	// 在 heap 中的位置，-1 代表已經被取出或取消
+	index int
+
+	timers *timerQueue
+}
+
+// 取消延遲任務，若任務已經被派發或已經取消就返回 false
+func (t *Timer) Stop() bool {
+	return t.timers.remove(t)
+}
+
+// 依照執行時間排序的 min-heap
+type timerHeap []*Timer
+
+func (h timerHeap) Len() int           { return len(h) }
+func (h timerHeap) Less(i, j int) bool { return h[i].when.Before(h[j].when) }
+func (h timerHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *timerHeap) Push(x interface{}) {
+	t := x.(*Timer)
+	t.index = len(*h)
+	*h = append(*h, t)
+}
+
+func (h *timerHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	t := old[n-1]
+	old[n-1] = nil // 避免記憶體溢出
+	t.index = -1
+	*h = old[:n-1]
+	return t
+}
+
+// Pool 擁有的延遲任務佇列，由單一 goroutine 負責派發
+type timerQueue struct {
+	lock sync.Mutex
+
+	timers timerHeap
+
+	// 有更早的任務加入時，喚醒派發的 goroutine
+	wakeup chan struct{}
+
+	// 停止派發的 goroutine
+	stop context.CancelFunc
+}
+
+// 加入延遲任務，必要時啟動派發的 goroutine，epoch 與 Pool 目前的不同代表中間已經被 Release 過
+func (tq *timerQueue) add(p *Pool, t *Timer, epoch uint32) error {
+	tq.lock.Lock()
+	// Release 會先關閉 Pool 再 reset，在鎖內檢查可以避免 reset 之後又啟動新的 goroutine
+	if p.IsClosed() || atomic.LoadUint32(&p.epoch) != epoch {
+		tq.lock.Unlock()
+		return ErrPoolClosed
+	}
+	if tq.stop == nil {
+		var ctx context.Context
+		ctx, tq.stop = context.WithCancel(context.Background())
+		tq.wakeup = make(chan struct{}, 1)
+		go p.dispatchTimers(ctx, tq.wakeup)
+	}
+	heap.Push(&tq.timers, t)
+	first := t.index == 0
+	tq.lock.Unlock()
+
+	if first {
+		select {
+		case tq.wakeup <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+// 從佇列中移除延遲任務
+func (tq *timerQueue) remove(t *Timer) bool {
+	tq.lock.Lock()
+	defer tq.lock.Unlock()
+	if t.index < 0 {
+		return false
+	}
+	heap.Remove(&tq.timers, t.index)
+	return true
+}
+
+// 取出所有到期的任務，並返回距離下一個任務的等待時間，-1 代表沒有任務
+func (tq *timerQueue) popExpired(now time.Time) ([]*Timer, time.Duration) {
+	tq.lock.Lock()
+	defer tq.lock.Unlock()
+
+	var expired []*Timer
+	for len(tq.timers) > 0 && !tq.timers[0].when.After(now) {
+		expired = append(expired, heap.Pop(&tq.timers).(*Timer))
+	}
+	if len(tq.timers) == 0 {
+		return expired, -1
+	}
+	return expired, tq.timers[0].when.Sub(now)
+}
+
+// 停止派發的 goroutine，並丟棄所有尚未執行的任務
+func (tq *timerQueue) reset() {
+	tq.lock.Lock()
+	defer tq.lock.Unlock()
+
+	if tq.stop != nil {
+		tq.stop()
+		tq.stop = nil
+	}
+	for i := range tq.timers {
+		tq.timers[i].index = -1
+		tq.timers[i] = nil
+	}
+	tq.timers = tq.timers[:0]
+}
+
+// 在 d 時間後將任務交給 Pool 執行
+func (p *Pool) ScheduleAfter(d time.Duration, task func()) (*Timer, error) {
+	return p.ScheduleAt(time.Now().Add(d), task)
+}
+
+// 在時間 t 將任務交給 Pool 執行，到期時沒有可用的 Worker 就放進任務佇列等待，不受 TaskQueueSize 限制，
+// 也不會交給 RejectionPolicy 處理，所以 Stop 返回 false 之後任務一定會執行，除非 Pool 被 Release
+func (p *Pool) ScheduleAt(t time.Time, task func()) (*Timer, error) {
+	if err := p.checkAccepting(); err != nil {
+		return nil, err
+	}
+	return p.scheduleAt(t, task, nil, atomic.LoadUint32(&p.epoch))
+}
+
+// 只有在 Pool 沒有被 Release 過時才加入延遲任務，避免 Reboot 之後延續舊的任務
+func (p *Pool) scheduleAt(t time.Time, task func(), due func() bool, epoch uint32) (*Timer, error) {
+	if task == nil {
+		return nil, ErrLackPoolFunc
+	}
+	if p.IsClosed() {
+		return nil, ErrPoolClosed
+	}
+
+	timer := &Timer{
+		when:   t,
+		task:   task,
+		due:    due,
+		timers: &p.timers,
+	}
+	if err := p.timers.add(p, timer, epoch); err != nil {
+		return nil, err
+	}
+	return timer, nil
+}
+
+// 派發到期的延遲任務，不會等待 Worker，沒有可用的 Worker 時放進任務佇列，避免一個任務擋住其他到期的任務
+func (p *Pool) dispatchTimers(ctx context.Context, wakeup <-chan struct{}) {
+	for {
+		// Release 之後可能已經有新的 goroutine 接手
+		if ctx.Err() != nil {
+			return
+		}
+
+		expired, wait := p.timers.popExpired(time.Now())
+		for _, t := range expired {
+			// 只有 Pool 已經被關閉時任務才會被丟棄
+			if t.due == nil || t.due() {
+				_ = p.handoff(t.task)
+			}
+			t.task, t.due = nil, nil
+		}
+		if len(expired) > 0 {
+			continue
+		}
+
+		var (
+			timer   *time.Timer
+			timeout <-chan time.Time
+		)
+		if wait >= 0 {
+			timer = time.NewTimer(wait)
+			timeout = timer.C
+		}
+
+		select {
+		case <-ctx.Done():
+			if timer != nil {
+				timer.Stop()
+			}
+			return
+		case <-wakeup:
+		case <-timeout:
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package grpool
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScheduleAfter(t *testing.T) {
+	p, _ := NewPool(10)
+	defer p.Release()
+
+	done := make(chan time.Time, 1)
+	start := time.Now()
+	_, err := p.ScheduleAfter(50*time.Millisecond, func() {
+		done <- time.Now()
+	})
+	assert.NoError(t, err)
+
+	select {
+	case at := <-done:
+		assert.GreaterOrEqual(t, at.Sub(start), 50*time.Millisecond)
+	case <-time.After(time.Second):
+		t.Fatal("delayed task was not executed")
+	}
+}
+
+func TestScheduleAtOrder(t *testing.T) {
+	p, _ := NewPool(1)
+	defer p.Release()
+
+	now := time.Now()
+	result := make(chan int, 3)
+	for _, i := range []int{3, 1, 2} {
+		i := i
+		_, _ = p.ScheduleAt(now.Add(time.Duration(i)*20*time.Millisecond), func() {
+			result <- i
+		})
+	}
+
+	for i := 1; i <= 3; i++ {
+		select {
+		case got := <-result:
+			assert.EqualValues(t, i, got)
+		case <-time.After(time.Second):
+			t.Fatal("delayed task was not executed")
+		}
+	}
+}
+
+func TestTimerStop(t *testing.T) {
+	p, _ := NewPool(10)
+	defer p.Release()
+
+	var count int32
+	timer, _ := p.ScheduleAfter(50*time.Millisecond, func() {
+		atomic.AddInt32(&count, 1)
+	})
+	assert.True(t, timer.Stop())
+	assert.False(t, timer.Stop())
+
+	time.Sleep(100 * time.Millisecond)
+	assert.EqualValues(t, 0, atomic.LoadInt32(&count))
+}
+
+func TestScheduleAfterRelease(t *testing.T) {
+	p, _ := NewPool(10)
+
+	var count int32
+	timer, _ := p.ScheduleAfter(50*time.Millisecond, func() {
+		atomic.AddInt32(&count, 1)
+	})
+	p.Release()
+	assert.False(t, timer.Stop())
+
+	_, err := p.ScheduleAfter(time.Millisecond, func() {})
+	assert.ErrorIs(t, err, ErrPoolClosed)
+
+	time.Sleep(100 * time.Millisecond)
+	assert.EqualValues(t, 0, atomic.LoadInt32(&count))
+
+	p.Reboot()
+	defer p.Release()
+	done := make(chan struct{})
+	_, err = p.ScheduleAfter(time.Millisecond, func() { close(done) })
+	assert.NoError(t, err)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("delayed task was not executed after reboot")
+	}
+}
+
+func TestScheduleAfterFullPool(t *testing.T) {
+	for _, nonblocking := range []bool{false, true} {
+		p, _ := NewPool(1, WithNonblocking(nonblocking))
+		release := occupyPool(p, 1)
+
+		// 到期的任務不會擋住派發的 goroutine，也不會被丟棄
+		var count int32
+		for i := 0; i < 3; i++ {
+			_, err := p.ScheduleAfter(time.Duration(i+1)*5*time.Millisecond, func() {
+				atomic.AddInt32(&count, 1)
+			})
+			assert.NoError(t, err)
+		}
+		assert.Eventually(t, func() bool { return p.Queued() == 3 }, time.Second, 5*time.Millisecond)
+		assert.Equal(t, 0, p.Waiting())
+
+		release()
+		assert.Eventually(t, func() bool { return atomic.LoadInt32(&count) == 3 }, time.Second, 5*time.Millisecond)
+		p.Release()
+	}
+}
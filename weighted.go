@@ -0,0 +1,178 @@
+package grpool
+
+import "sync/atomic"
+
+// 等待容量的加權任務
+type weightedWaiter struct {
+	weight int
+	task   func()
+
+	// 任務被派發或 Pool 被關閉時關閉
+	ready chan struct{}
+	err   error
+}
+
+// 加權任務的狀態，所有欄位都由 p.lock 保護
+type weightedState struct {
+	// 執行中的加權任務額外佔用的容量，也就是 weight - 1 的總和
+	extra int
+
+	// 依照提交順序等待容量的加權任務
+	waiters []*weightedWaiter
+}
+
+// 依照 weight 佔用 Pool 的容量執行任務，weight 為 1 時與 Schedule 相同，
+// 容量不足時 Blocking 模式會依照提交順序等待，排在前面的加權任務等待時，之後提交的任務也會等待，避免加權任務被餓死，
+// 在任務裡面提交時不會等待: NestedCallerRuns 或 WorkStealing 直接在提交的 Worker 上執行，NestedQueue 排隊後直接返回，
+// 暫停中的 Nonblocking Pool 也是排隊後直接返回，Resume 之後執行
+func (p *Pool) ScheduleWeighted(weight int, task func()) error {
+	if task == nil {
+		return ErrLackPoolFunc
+	}
+	if weight <= 0 {
+		return ErrInvalidWeight
+	}
+	if err := p.checkAccepting(); err != nil {
+		return err
+	}
+	// 沒有容量上限時 weight 沒有意義
+	if capacity := p.Cap(); weight == 1 || capacity == -1 {
+		return p.schedule(task)
+	} else if weight > capacity {
+		return ErrInvalidWeight
+	}
+	nested := p.nestedWorker() != nil
+	if p.memory != nil && !p.waitMemory(!p.currentOptions().Nonblocking && !nested) {
+		if p.IsClosed() {
+			return ErrPoolClosed
+		}
+		return ErrMemoryLimitExceeded
+	}
+
+	waiter := &weightedWaiter{
+		weight: weight,
+		task: func() {
+			defer p.releaseWeight(weight)
+			task()
+		},
+		ready: make(chan struct{}),
+	}
+
+	p.lock.Lock()
+	// 在鎖內檢查，避免 Release 之後才加入等待
+	if err := p.checkAccepting(); err != nil {
+		p.lock.Unlock()
+		return err
+	}
+	if len(p.weighted.waiters) == 0 && p.fitsWeight(weight) {
+		p.reserveWeight(weight)
+		p.lock.Unlock()
+		p.dispatchWeighted(waiter)
+		return nil
+	}
+	// 提交的 Worker 等待容量時，所有 Worker 都可能在等待而死鎖
+	if nested && p.nestedCallerRuns() {
+		p.lock.Unlock()
+		task()
+		return nil
+	}
+	// 巢狀提交或暫停中的 Nonblocking Pool 排隊後直接返回，不等待派發
+	if nested || p.currentOptions().Nonblocking && p.Paused() {
+		p.weighted.waiters = append(p.weighted.waiters, waiter)
+		p.lock.Unlock()
+		return nil
+	}
+	// 加權任務不交給 RejectionPolicy 處理，避免被當成一般任務重新提交
+	if p.currentOptions().Nonblocking {
+		p.lock.Unlock()
+		return ErrPoolOverload
+	}
+	p.weighted.waiters = append(p.weighted.waiters, waiter)
+	p.lock.Unlock()
+
+	atomic.AddInt32(&p.waiting, 1)
+	<-waiter.ready
+	p.doneWaiting()
+	return waiter.err
+}
+
+// 剩下的容量是否足夠且沒有暫停，上限為 Pool 容量與 ConcurrencyLimiter 上限的較小值，
+// Tune 或上限下降之後 weight 可能超過上限，此時等到沒有任務在執行時才派發，呼叫時必須持有 p.lock
+func (p *Pool) fitsWeight(weight int) bool {
+	if p.Paused() {
+		return false
+	}
+	limit := p.Cap()
+	if p.limiter != nil {
+		if l := p.limiter.Limit(); l < limit {
+			limit = l
+		}
+	}
+	if weight > limit {
+		weight = limit
+	}
+	return int(atomic.LoadInt32(&p.inflight))+p.weighted.extra+weight <= limit
+}
+
+// 預先佔用容量，呼叫時必須持有 p.lock
+func (p *Pool) reserveWeight(weight int) {
+	p.addInflight(1)
+	p.weighted.extra += weight - 1
+}
+
+// 加權任務完成後釋放額外佔用的容量，並喚醒等待 Worker 的呼叫
+func (p *Pool) releaseWeight(weight int) {
+	p.lock.Lock()
+	p.weighted.extra -= weight - 1
+	p.lock.Unlock()
+	p.cond.Broadcast()
+}
+
+// 依照順序取出容量足夠的加權任務並預先佔用容量，遇到容量不足的任務就停止，呼叫時必須持有 p.lock
+func (p *Pool) grantWeighted() []*weightedWaiter {
+	var granted []*weightedWaiter
+	for len(p.weighted.waiters) > 0 {
+		waiter := p.weighted.waiters[0]
+		if !p.fitsWeight(waiter.weight) {
+			break
+		}
+		p.reserveWeight(waiter.weight)
+		p.weighted.waiters[0] = nil // 避免記憶體溢出
+		p.weighted.waiters = p.weighted.waiters[1:]
+		granted = append(granted, waiter)
+	}
+	return granted
+}
+
+// 把已經佔用容量的加權任務交給閒置的 Worker 或新的 Worker
+func (p *Pool) dispatchWeighted(waiter *weightedWaiter) {
+	p.lock.Lock()
+	w := p.workers.detach()
+	p.lock.Unlock()
+	if w == nil {
+		w = p.workerCache.Get().(*Worker)
+		w.run()
+	}
+	w.inputFunc(waiter.task)
+	close(waiter.ready)
+}
+
+// 容量變大後派發等待中的加權任務
+func (p *Pool) wakeWeighted() {
+	p.lock.Lock()
+	granted := p.grantWeighted()
+	p.lock.Unlock()
+	for _, waiter := range granted {
+		p.dispatchWeighted(waiter)
+	}
+}
+
+// Pool 被關閉時讓等待中的加權任務返回 ErrPoolClosed，呼叫時必須持有 p.lock
+func (p *Pool) resetWeighted() {
+	for i, waiter := range p.weighted.waiters {
+		waiter.err = ErrPoolClosed
+		close(waiter.ready)
+		p.weighted.waiters[i] = nil
+	}
+	p.weighted.waiters = p.weighted.waiters[:0]
+}
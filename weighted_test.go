@@ -0,0 +1,136 @@
+package grpool
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScheduleWeighted(t *testing.T) {
+	p, _ := NewPool(4, WithNonblocking(true))
+	defer p.Release()
+
+	assert.ErrorIs(t, p.ScheduleWeighted(0, demoFunc), ErrInvalidWeight)
+	assert.ErrorIs(t, p.ScheduleWeighted(5, demoFunc), ErrInvalidWeight)
+
+	block := make(chan struct{})
+	started := make(chan struct{})
+	assert.NoError(t, p.ScheduleWeighted(3, func() {
+		close(started)
+		<-block
+	}))
+	<-started
+
+	// 加權任務佔用了 3 個容量，只剩下 1 個
+	release := occupyPool(p, 1)
+	assert.ErrorIs(t, p.Schedule(demoFunc), ErrPoolOverload)
+	assert.ErrorIs(t, p.ScheduleWeighted(2, demoFunc), ErrPoolOverload)
+
+	// 釋放之後容量恢復
+	close(block)
+	release()
+	time.Sleep(20 * time.Millisecond)
+	assert.NoError(t, p.ScheduleWeighted(4, demoFunc))
+}
+
+func TestScheduleWeightedFairness(t *testing.T) {
+	p, _ := NewPool(4)
+	defer p.Release()
+	release := occupyPool(p, 4)
+
+	var (
+		lock  sync.Mutex
+		order []int
+		wg    sync.WaitGroup
+		ran   sync.WaitGroup
+	)
+	ran.Add(11)
+	record := func(weight int) func() {
+		return func() {
+			defer ran.Done()
+			lock.Lock()
+			order = append(order, weight)
+			lock.Unlock()
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		assert.NoError(t, p.ScheduleWeighted(4, record(4)))
+	}()
+	for p.Waiting() != 1 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// 之後提交的任務必須排在加權任務後面
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, p.Schedule(record(1)))
+		}()
+	}
+	for p.Waiting() != 11 {
+		time.Sleep(time.Millisecond)
+	}
+
+	release()
+	wg.Wait()
+	ran.Wait()
+
+	assert.Len(t, order, 11)
+	assert.Equal(t, 4, order[0], "heavy task should not be starved by weight-1 tasks")
+}
+
+func TestScheduleWeightedRelease(t *testing.T) {
+	p, _ := NewPool(2)
+	release := occupyPool(p, 2)
+	defer release()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- p.ScheduleWeighted(2, demoFunc)
+	}()
+	for p.Waiting() != 1 {
+		time.Sleep(time.Millisecond)
+	}
+
+	p.Release()
+	assert.ErrorIs(t, <-done, ErrPoolClosed)
+}
+
+func TestScheduleWeightedLimiter(t *testing.T) {
+	p, _ := NewPool(4, WithNonblocking(true), WithConcurrencyLimiter(&fixedLimiter{limit: 2}))
+	defer p.Release()
+	release := occupyPool(p, 1)
+
+	// 容量還有 3 個，但 ConcurrencyLimiter 只剩下 1 個
+	assert.ErrorIs(t, p.ScheduleWeighted(2, demoFunc), ErrPoolOverload)
+	assert.EqualValues(t, 1, atomic.LoadInt32(&p.inflight))
+
+	release()
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(&p.inflight) == 0 }, time.Second, time.Millisecond)
+	assert.NoError(t, p.ScheduleWeighted(2, demoFunc))
+}
+
+func TestScheduleWeightedMemoryLimit(t *testing.T) {
+	var usage uint64 = 2000
+	p, err := NewPool(4, WithMemoryLimit(MemoryLimit{
+		SoftLimit: 1000,
+		Usage:     func() uint64 { return atomic.LoadUint64(&usage) },
+		Interval:  time.Millisecond,
+	}))
+	assert.NoError(t, err)
+	defer p.Release()
+
+	assert.ErrorIs(t, p.ScheduleWeighted(2, demoFunc), ErrMemoryLimitExceeded)
+
+	atomic.StoreUint64(&usage, 100)
+	time.Sleep(2 * time.Millisecond)
+	assert.NoError(t, p.ScheduleWeighted(2, demoFunc))
+}
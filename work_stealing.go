@@ -0,0 +1,222 @@
+package grpool
+
+import (
+	"math/rand"
+	"sync"
+	"sync/atomic"
+)
+
+// Worker 自己的任務 deque，擁有者從尾端存取，其他 Worker 從頭端偷取
+type taskDeque struct {
+	lock  sync.Mutex
+	tasks []func()
+}
+
+func (d *taskDeque) pushBottom(task func()) {
+	d.lock.Lock()
+	d.tasks = append(d.tasks, task)
+	d.lock.Unlock()
+}
+
+// 擁有者取出最後加入的任務，讓剛產生的子任務優先執行
+func (d *taskDeque) popBottom() func() {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	n := len(d.tasks)
+	if n == 0 {
+		return nil
+	}
+	task := d.tasks[n-1]
+	d.tasks[n-1] = nil // 避免記憶體溢出
+	d.tasks = d.tasks[:n-1]
+	return task
+}
+
+// 其他 Worker 偷取最早加入的任務
+func (d *taskDeque) popTop() func() {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	if len(d.tasks) == 0 {
+		return nil
+	}
+	task := d.tasks[0]
+	d.tasks[0] = nil // 避免記憶體溢出
+	d.tasks = d.tasks[1:]
+	if len(d.tasks) == 0 {
+		d.tasks = nil
+	}
+	return task
+}
+
+// 取出所有任務
+func (d *taskDeque) drain() []func() {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	tasks := d.tasks
+	d.tasks = nil
+	return tasks
+}
+
+// 參與 work stealing 或需要判斷巢狀提交的 Worker
+type stealRegistry struct {
+	lock sync.RWMutex
+
+	workers []*Worker
+
+	// goroutine id 對應的 Worker，用來判斷任務是否從 Worker 裡面提交，讀取遠多於寫入所以使用 sync.Map
+	byGoid sync.Map
+
+	// 所有 deque 中的任務數量，為 0 時不需要偷取
+	pending int32
+}
+
+// Worker 開始執行時註冊
+func (r *stealRegistry) register(w *Worker) {
+	w.goid = goid()
+
+	r.byGoid.Store(w.goid, w)
+	r.lock.Lock()
+	r.workers = append(r.workers, w)
+	r.lock.Unlock()
+}
+
+// Worker 退出時取消註冊，並返回 deque 中還沒執行的任務，pending 由 requeue 扣掉
+func (r *stealRegistry) unregister(w *Worker) []func() {
+	r.byGoid.Delete(w.goid)
+	r.lock.Lock()
+	for i := range r.workers {
+		if r.workers[i] == w {
+			last := len(r.workers) - 1
+			r.workers[i] = r.workers[last]
+			r.workers[last] = nil
+			r.workers = r.workers[:last]
+			break
+		}
+	}
+	r.lock.Unlock()
+
+	return w.local.drain()
+}
+
+// 獲取目前 goroutine 所在的 Worker，不在 Worker 中就返回 nil
+func (r *stealRegistry) current() *Worker {
+	if w, ok := r.byGoid.Load(goid()); ok {
+		return w.(*Worker)
+	}
+	return nil
+}
+
+// 加入 Worker 自己的 deque
+func (r *stealRegistry) push(w *Worker, task func()) {
+	atomic.AddInt32(&r.pending, 1)
+	w.local.pushBottom(task)
+}
+
+// 先取出自己 deque 中的任務，沒有的話就從其他 Worker 偷取，pending 由 claimStolen 扣掉
+func (r *stealRegistry) pop(self *Worker) func() {
+	if atomic.LoadInt32(&r.pending) == 0 {
+		return nil
+	}
+	if task := self.local.popBottom(); task != nil {
+		return task
+	}
+	return r.steal(self)
+}
+
+// 從隨機的位置開始，依序偷取其他 Worker 的任務
+func (r *stealRegistry) steal(self *Worker) func() {
+	if atomic.LoadInt32(&r.pending) == 0 {
+		return nil
+	}
+
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	n := len(r.workers)
+	if n == 0 {
+		return nil
+	}
+	start := rand.Intn(n)
+	for i := 0; i < n; i++ {
+		w := r.workers[(start+i)%n]
+		if w == self {
+			continue
+		}
+		if task := w.local.popTop(); task != nil {
+			return task
+		}
+	}
+	return nil
+}
+
+// 從 Worker 裡面提交的任務放進自己的 deque，並喚醒閒置的 Worker 來偷取，提交的 Worker 不會被阻塞，
+// 沒有閒置的 Worker 且 Pool 已滿時直接在提交的 Worker 上執行自己 deque 中的任務，
+// 避免所有 Worker 都在等待子任務完成，卻沒有 Worker 可以偷取而死鎖
+func (p *Pool) scheduleLocal(w *Worker, task func()) {
+	p.stealing.push(w, task)
+	// 暫停中不派發也不執行，Resume 時會再喚醒
+	if p.Paused() || p.wakeStealer() {
+		return
+	}
+	// 可能已經被其他 Worker 偷走，此時取出的是自己更早提交的任務
+	if task := w.local.popBottom(); task != nil {
+		atomic.AddInt32(&p.stealing.pending, -1)
+		task()
+	}
+}
+
+// 取出 deque 中的任務交給 Worker 執行，先計入 in-flight 再扣掉 pending，
+// 避免 checkDrained 或 WaitIdle 在中間看到兩者都是 0，呼叫時必須持有 p.lock
+func (p *Pool) claimStolen(task func()) func() {
+	if task != nil {
+		p.addInflight(1)
+		atomic.AddInt32(&p.stealing.pending, -1)
+	}
+	return task
+}
+
+// Worker 執行完任務後接著取出 deque 中的任務，與派發新的任務相同受到 admit() 的限制，例如 ConcurrencyLimiter
+func (p *Pool) popStolen(w *Worker) func() {
+	if atomic.LoadInt32(&p.stealing.pending) == 0 {
+		return nil
+	}
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if !p.admit() {
+		return nil
+	}
+	return p.claimStolen(p.stealing.pop(w))
+}
+
+// 喚醒一個閒置的 Worker，或是在 Pool 沒有滿時開啟新的 Worker 來偷取任務，
+// 都在忙碌中、暫停中或 admit() 不允許派發時返回 false
+func (p *Pool) wakeStealer() bool {
+	p.lock.Lock()
+	w, spawn := p.reserveWorker()
+	p.lock.Unlock()
+	if w == nil && !spawn {
+		return false
+	}
+
+	if spawn {
+		w = p.spawnWorker()
+	}
+	w.inputFunc(func() {})
+	return true
+}
+
+// 把退出的 Worker 還沒執行的任務放回 Pool 的佇列，這些任務已經被接受，不能再被丟棄
+func (p *Pool) requeue(tasks []func()) {
+	p.lock.Lock()
+	if !p.IsClosed() {
+		for i := range tasks {
+			p.tasks.forcePush(tasks[i], true)
+		}
+	}
+	// 放進佇列之後才扣掉 pending，避免中間被判斷為閒置
+	atomic.AddInt32(&p.stealing.pending, -int32(len(tasks)))
+	p.lock.Unlock()
+}
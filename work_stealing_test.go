@@ -0,0 +1,173 @@
+package grpool
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGoid(t *testing.T) {
+	id := goid()
+	assert.NotZero(t, id)
+	assert.Equal(t, id, goid())
+
+	ch := make(chan uint64)
+	go func() { ch <- goid() }()
+	assert.NotEqual(t, id, <-ch)
+}
+
+func TestTaskDeque(t *testing.T) {
+	var d taskDeque
+	result := make([]int, 0, 4)
+	for i := 0; i < 4; i++ {
+		i := i
+		d.pushBottom(func() { result = append(result, i) })
+	}
+	d.popBottom()()
+	d.popTop()()
+	d.popBottom()()
+	d.popTop()()
+	assert.Nil(t, d.popBottom())
+	assert.Nil(t, d.popTop())
+	assert.EqualValues(t, []int{3, 0, 2, 1}, result)
+}
+
+// 每個任務都在裡面提交子任務，Pool 滿載時也不能死鎖
+func TestWorkStealingNestedSubmission(t *testing.T) {
+	const workers, subtasks = 4, 1000
+	p, _ := NewPool(workers, WithWorkStealing(true))
+	defer p.Release()
+
+	var (
+		wg    sync.WaitGroup
+		count int32
+	)
+	wg.Add(workers * (subtasks + 1))
+	for i := 0; i < workers; i++ {
+		_ = p.Schedule(func() {
+			defer wg.Done()
+			for j := 0; j < subtasks; j++ {
+				assert.NoError(t, p.Schedule(func() {
+					atomic.AddInt32(&count, 1)
+					wg.Done()
+				}))
+			}
+		})
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("nested submission deadlocked")
+	}
+	assert.EqualValues(t, workers*subtasks, atomic.LoadInt32(&count))
+	assert.LessOrEqual(t, p.Running(), workers)
+}
+
+// 所有 Worker 都在等待自己提交的子任務時，子任務會在提交的 Worker 上執行
+func TestWorkStealingParentsWaitForChildren(t *testing.T) {
+	const workers = 4
+	p, _ := NewPool(workers, WithWorkStealing(true))
+	defer p.Release()
+
+	done, errs := scheduleNestedAtCapacity(p, workers)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("parents waiting for their children deadlocked")
+	}
+	for i := 0; i < workers; i++ {
+		assert.NoError(t, <-errs)
+	}
+}
+
+// 子任務會被其他閒置的 Worker 偷走平行執行
+func TestWorkStealingSteal(t *testing.T) {
+	const workers, subtasks = 4, 8
+	p, _ := NewPool(workers, WithWorkStealing(true))
+	defer p.Release()
+
+	var (
+		wg   sync.WaitGroup
+		lock sync.Mutex
+		ids  = make(map[uint64]struct{})
+	)
+	wg.Add(subtasks)
+	_ = p.Schedule(func() {
+		for j := 0; j < subtasks; j++ {
+			_ = p.Schedule(func() {
+				defer wg.Done()
+				lock.Lock()
+				ids[goid()] = struct{}{}
+				lock.Unlock()
+				time.Sleep(20 * time.Millisecond)
+			})
+		}
+		// 提交的 Worker 自己也在忙碌中
+		time.Sleep(50 * time.Millisecond)
+	})
+	wg.Wait()
+
+	assert.Greater(t, len(ids), 1, "subtasks should be stolen by other workers")
+}
+
+func TestWorkStealingWaitIdle(t *testing.T) {
+	p, _ := NewPool(4, WithWorkStealing(true))
+	defer p.Release()
+
+	// 子任務從 deque 取出到開始執行之間不能被判斷為閒置
+	var ran int32
+	for i := 1; i <= 200; i++ {
+		assert.NoError(t, p.Schedule(func() {
+			for j := 0; j < 4; j++ {
+				_ = p.Schedule(func() { atomic.AddInt32(&ran, 1) })
+			}
+		}))
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		assert.NoError(t, p.WaitIdle(ctx))
+		cancel()
+		if !assert.EqualValues(t, i*4, atomic.LoadInt32(&ran)) {
+			return
+		}
+	}
+}
+
+func TestWorkStealingRespectsLimiter(t *testing.T) {
+	p, _ := NewPool(8, WithWorkStealing(true), WithConcurrencyLimiter(&fixedLimiter{limit: 1}))
+	defer p.Release()
+
+	// 偷取的子任務也受到 ConcurrencyLimiter 的限制
+	var running, peak, ran int32
+	subtask := func() {
+		n := atomic.AddInt32(&running, 1)
+		for {
+			old := atomic.LoadInt32(&peak)
+			if n <= old || atomic.CompareAndSwapInt32(&peak, old, n) {
+				break
+			}
+		}
+		time.Sleep(2 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+		atomic.AddInt32(&ran, 1)
+	}
+	assert.NoError(t, p.Schedule(func() {
+		for i := 0; i < 8; i++ {
+			_ = p.Schedule(subtask)
+		}
+	}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	assert.NoError(t, p.WaitIdle(ctx))
+	assert.EqualValues(t, 8, atomic.LoadInt32(&ran))
+	assert.EqualValues(t, 1, atomic.LoadInt32(&peak))
+}
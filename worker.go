@@ -3,6 +3,7 @@ package grpool
 import (
 	"fmt"
 	"runtime/debug"
+	"sync/atomic"
 	"time"
 )
 
@@ -22,25 +23,54 @@ type Worker struct {
 
 	// 回收時間
 	lastUpdatedTime time.Time
+
+	// work stealing 模式下，從這個 Worker 裡面提交的任務
+	local taskDeque
+
+	// 執行中的 goroutine id
+	goid uint64
 }
 
 func (w *Worker) run() {
 	w.pool.addRunning(1)
 	go func() {
+		tracked, stealing := w.pool.tracksWorkers(), w.pool.currentOptions().WorkStealing
+		if tracked {
+			w.pool.stealing.register(w)
+		}
+
 		// 回收 Pool 失敗或 worker 發生錯誤
 		defer func() {
+			if tracked {
+				// 還沒執行的子任務交給其他 Worker
+				if tasks := w.pool.stealing.unregister(w); len(tasks) > 0 {
+					w.pool.requeue(tasks)
+				}
+			}
 			w.pool.addRunning(-1)
 			// worker 放 cache 可以不用重新初始化
 			w.pool.workerCache.Put(w)
 			if p := recover(); p != nil {
-				if ph := w.pool.options.PanicHandler; ph != nil {
+				if ph := w.pool.currentOptions().PanicHandler; ph != nil {
 					ph(p)
 				} else {
 					fmt.Printf("worker exited from panic: %v\n%s\n", p, debug.Stack())
 				}
 			}
-			// 喚醒 Blocking 的 task
-			w.pool.cond.Signal()
+			// 還有排隊中的任務就補上新的 Worker
+			w.pool.dispatchQueued()
+			w.pool.wakeWeighted()
+			w.pool.wakeTenants()
+			w.pool.checkDrained()
+			w.pool.notifyIdle()
+			// 喚醒 Blocking 的 task，Pool 關閉後要喚醒等待所有 Worker 退出的 awaitStopped
+			if w.pool.IsClosed() {
+				w.pool.lock.Lock()
+				w.pool.cond.Broadcast()
+				w.pool.lock.Unlock()
+			} else {
+				w.pool.cond.Signal()
+			}
 		}()
 
 		// 監聽任務列表，有任務就拿出來執行
@@ -50,17 +80,40 @@ func (w *Worker) run() {
 				return
 			}
 
-			// 執行任務
-			f()
+			for f != nil {
+				// 執行任務
+				w.exec(f)
 
-			// 回收worker
-			if ok := w.pool.putWorker(w); !ok {
-				return
+				// work stealing 模式先執行自己 deque 中的任務，再從其他 Worker 偷取
+				if stealing && !w.pool.IsClosed() && !w.pool.Paused() {
+					if f = w.pool.popStolen(w); f != nil {
+						continue
+					}
+				}
+
+				// 回收worker，若有排隊中的任務就接著執行
+				var ok bool
+				if f, ok = w.pool.putWorker(w); !ok {
+					return
+				}
 			}
 		}
 	}()
 }
 
+// 執行任務，需要時記錄執行時間，完成或 panic 後釋放 in-flight 的計數
+func (w *Worker) exec(f func()) {
+	defer w.pool.finishInflight()
+
+	if !w.pool.observesLatency() {
+		f()
+		return
+	}
+	start := time.Now()
+	f()
+	w.pool.observeLatency(time.Since(start), int(atomic.LoadInt32(&w.pool.inflight)))
+}
+
 func (w *Worker) finish() {
 	w.task <- nil
 }
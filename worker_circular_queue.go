@@ -18,7 +18,7 @@ type workerQueue interface {
 	isEmpty() bool
 	insert(worker) error
 	detach() worker
-	refresh(duration time.Duration) []worker
+	refresh(duration time.Duration, keep, max int) []worker
 	reset()
 }
 
@@ -85,8 +85,8 @@ func (wq *circularQueue) insert(w worker) error {
 		return errQueueIsFull
 	}
 
-	// 增加 Worker
-	if !wq.isPreAlloc && cap(wq.items) <= wq.size {
+	// 增加 Worker，未提前申請空間時，只有 tail 走到 slice 尾端才需要 append
+	if !wq.isPreAlloc && len(wq.items) == wq.tail {
 		wq.items = append(wq.items, w)
 	} else {
 		wq.items[wq.tail] = w
@@ -119,14 +119,33 @@ func (wq *circularQueue) detach() worker {
 	return w
 }
 
-// 重新整理 Queue，用於清理過期的 worker
-func (wq *circularQueue) refresh(duration time.Duration) []worker {
+// 重新整理 Queue，用於清理過期的 worker，至少會保留 keep 個 worker，且最多清理 max 個，max 為 0 代表沒有限制
+func (wq *circularQueue) refresh(duration time.Duration, keep, max int) []worker {
 	expiryTime := time.Now().Add(-duration)
 	// 獲取過期 worker 的 index
 	index := wq.binarySearch(expiryTime)
 	if index == -1 {
 		return nil
 	}
+
+	// 受到 keep 或 max 的限制時，只清理最舊的那幾個
+	if keep > 0 || max > 0 {
+		expired := index - wq.head + 1
+		if wq.head > index {
+			expired = wq.size - wq.head + index + 1
+		}
+		limit := expired
+		if remain := wq.len() - expired; remain < keep {
+			limit -= keep - remain
+		}
+		if max > 0 && limit > max {
+			limit = max
+		}
+		if limit <= 0 {
+			return nil
+		}
+		index = (wq.head + limit - 1) % wq.size
+	}
 	wq.expiry = wq.expiry[:0]
 
 	if wq.head <= index {
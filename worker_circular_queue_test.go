@@ -42,7 +42,7 @@ func TestCircularQueue(t *testing.T) {
 	err := q.insert(&Worker{lastUpdatedTime: time.Now()})
 	assert.Error(t, err, "Enqueue, error")
 
-	q.refresh(time.Second)
+	q.refresh(time.Second, 0, 0)
 	assert.EqualValuesf(t, 6, q.len(), "Len error: %d", q.len())
 }
 
@@ -135,7 +135,7 @@ func TestRetrieveExpiry(t *testing.T) {
 	for i := 0; i < size/2; i++ {
 		_ = q.insert(&Worker{lastUpdatedTime: time.Now()})
 	}
-	workers := q.refresh(u)
+	workers := q.refresh(u, 0, 0)
 
 	assert.EqualValues(t, expirew, workers, "expired workers aren't right")
 
@@ -148,7 +148,7 @@ func TestRetrieveExpiry(t *testing.T) {
 	expirew = expirew[:0]
 	expirew = append(expirew, q.items[size/2:]...)
 
-	workers2 := q.refresh(u)
+	workers2 := q.refresh(u, 0, 0)
 
 	assert.EqualValues(t, expirew, workers2, "expired workers aren't right")
 
@@ -168,7 +168,69 @@ func TestRetrieveExpiry(t *testing.T) {
 	expirew = append(expirew, q.items[0:3]...)
 	expirew = append(expirew, q.items[size/2:]...)
 
-	workers3 := q.refresh(u)
+	workers3 := q.refresh(u, 0, 0)
 
 	assert.EqualValues(t, expirew, workers3, "expired workers aren't right")
 }
+
+func TestCircularQueueWrapAround(t *testing.T) {
+	size := 3
+	q := newWorkerCircularQueue(size, false)
+
+	for round := 0; round < 3; round++ {
+		for i := 0; i < size; i++ {
+			assert.NoError(t, q.insert(&Worker{lastUpdatedTime: time.Now()}), "Enqueue error")
+		}
+		assert.EqualValues(t, size, q.len(), "Len error")
+		for i := 0; i < size; i++ {
+			assert.NotNil(t, q.detach(), "Dequeue error")
+		}
+		assert.EqualValues(t, 0, q.len(), "Len error")
+	}
+	assert.EqualValues(t, size, len(q.items), "items should not grow beyond size")
+}
+
+func TestCircularQueueRefreshKeep(t *testing.T) {
+	size := 10
+	q := newWorkerCircularQueue(size, false)
+
+	for i := 0; i < 8; i++ {
+		_ = q.insert(&Worker{lastUpdatedTime: time.Now()})
+	}
+	time.Sleep(10 * time.Millisecond)
+
+	// 全部過期，但至少保留 3 個最新的
+	workers := q.refresh(time.Millisecond, 3, 0)
+	assert.Len(t, workers, 5, "expired workers aren't right")
+	assert.EqualValues(t, 3, q.len(), "Len error")
+
+	// 剩下的數量不超過 keep 時不清理
+	assert.Nil(t, q.refresh(time.Millisecond, 3, 0))
+	assert.EqualValues(t, 3, q.len(), "Len error")
+
+	// 繞回之後也要保留
+	for i := 0; i < 7; i++ {
+		_ = q.insert(&Worker{lastUpdatedTime: time.Now()})
+	}
+	time.Sleep(10 * time.Millisecond)
+	workers = q.refresh(time.Millisecond, 4, 0)
+	assert.Len(t, workers, 6, "expired workers aren't right")
+	assert.EqualValues(t, 4, q.len(), "Len error")
+}
+
+func TestCircularQueueRefreshMax(t *testing.T) {
+	size := 10
+	q := newWorkerCircularQueue(size, true)
+
+	for i := 0; i < 8; i++ {
+		_ = q.insert(&Worker{lastUpdatedTime: time.Now()})
+	}
+	time.Sleep(10 * time.Millisecond)
+
+	// 每次最多清理 3 個
+	assert.Len(t, q.refresh(time.Millisecond, 0, 3), 3, "expired workers aren't right")
+	assert.EqualValues(t, 5, q.len(), "Len error")
+	assert.Len(t, q.refresh(time.Millisecond, 2, 2), 2, "expired workers aren't right")
+	assert.Len(t, q.refresh(time.Millisecond, 2, 2), 1, "expired workers aren't right")
+	assert.EqualValues(t, 2, q.len(), "Len error")
+}
@@ -0,0 +1,146 @@
+package grpool
+
+import "time"
+
+// growableQueue 最小的容量
+const minGrowableQueueSize = 16
+
+// 沒有上限的 Pool 使用的 workerQueue，空間會依照閒置的 Worker 數量加倍或減半，
+// 不需要像 circularQueue 一樣事先申請 DefaultPoolSize 的空間
+type growableQueue struct {
+	items  []worker
+	expiry []worker
+	head   int
+	count  int
+}
+
+// 初始化 WorkerGrowableQueue，第一次 insert 時才會申請空間
+func newWorkerGrowableQueue() *growableQueue {
+	return &growableQueue{}
+}
+
+// 獲取 Queue 長度
+func (wq *growableQueue) len() int {
+	return wq.count
+}
+
+// 判斷 Queue 是否為空
+func (wq *growableQueue) isEmpty() bool {
+	return wq.count == 0
+}
+
+// 插入一個 worker 進入 Queue，滿了就把空間加倍，不會返回錯誤
+func (wq *growableQueue) insert(w worker) error {
+	if wq.count == len(wq.items) {
+		size := len(wq.items) * 2
+		if size < minGrowableQueueSize {
+			size = minGrowableQueueSize
+		}
+		wq.resize(size)
+	}
+
+	wq.items[(wq.head+wq.count)%len(wq.items)] = w
+	wq.count++
+	return nil
+}
+
+// 從 Queue 獲取一個 worker
+func (wq *growableQueue) detach() worker {
+	if wq.isEmpty() {
+		return nil
+	}
+
+	w := wq.items[wq.head]
+	wq.items[wq.head] = nil // 避免記憶體溢出
+	wq.head = (wq.head + 1) % len(wq.items)
+	wq.count--
+
+	wq.shrink()
+	return w
+}
+
+// 重新整理 Queue，用於清理過期的 worker，至少會保留 keep 個 worker，且最多清理 max 個，max 為 0 代表沒有限制
+func (wq *growableQueue) refresh(duration time.Duration, keep, max int) []worker {
+	expired := wq.binarySearch(time.Now().Add(-duration))
+	if remain := wq.count - expired; remain < keep {
+		expired -= keep - remain
+	}
+	if max > 0 && expired > max {
+		expired = max
+	}
+	if expired <= 0 {
+		return nil
+	}
+
+	// 因為 FIFO 的關係，越前面的 worker 越久沒有被使用
+	wq.expiry = wq.expiry[:0]
+	for i := 0; i < expired; i++ {
+		wq.expiry = append(wq.expiry, wq.items[wq.head])
+		wq.items[wq.head] = nil
+		wq.head = (wq.head + 1) % len(wq.items)
+	}
+	wq.count -= expired
+
+	// shrink 可能會丟掉過大的 expiry，先保留這次的結果
+	expiry := wq.expiry
+	wq.shrink()
+
+	// 返回這些過期 worker 要讓 pool 去手動 finish 它
+	return expiry
+}
+
+// 二元搜尋，返回過期的 worker 數量
+func (wq *growableQueue) binarySearch(expiryTime time.Time) int {
+	l, r := 0, wq.count
+	for l < r {
+		mid := l + (r-l)>>1
+		if expiryTime.Before(wq.items[(wq.head+mid)%len(wq.items)].getLastUpdatedTime()) {
+			r = mid
+		} else {
+			l = mid + 1
+		}
+	}
+	return l
+}
+
+// 閒置的 worker 少於容量的 1/4 時把空間減半，避免只減少一個就又要加倍
+func (wq *growableQueue) shrink() {
+	size := len(wq.items)
+	for size > minGrowableQueueSize && wq.count <= size/4 {
+		size /= 2
+	}
+	if size != len(wq.items) {
+		wq.resize(size)
+	}
+}
+
+// 依照順序搬到新的空間，head 從 0 開始
+func (wq *growableQueue) resize(size int) {
+	items := make([]worker, size)
+	for i := 0; i < wq.count; i++ {
+		items[i] = wq.items[(wq.head+i)%len(wq.items)]
+	}
+	wq.items = items
+	wq.head = 0
+
+	// 清理後留下的 expiry 也不需要比 Queue 大
+	if cap(wq.expiry) > size {
+		wq.expiry = nil
+	}
+}
+
+// 當 Pool 被 Release 後，就會觸發此方法，將所有 Worker queue 清理並釋放空間
+func (wq *growableQueue) reset() {
+	for {
+		if w := wq.detach(); w != nil {
+			w.finish()
+			continue
+		}
+		break
+	}
+
+	wq.items = nil
+	wq.expiry = nil
+	wq.head = 0
+	wq.count = 0
+}
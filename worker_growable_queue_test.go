@@ -0,0 +1,132 @@
+package grpool
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewGrowableQueue(t *testing.T) {
+	q := newWorkerGrowableQueue()
+	assert.EqualValues(t, 0, q.len(), "Len error")
+	assert.Equal(t, true, q.isEmpty(), "IsEmpty error")
+	assert.Nil(t, q.detach(), "Dequeue error")
+	assert.Nil(t, q.items, "no space should be allocated before insert")
+}
+
+func TestGrowableQueueGrowAndShrink(t *testing.T) {
+	q := newWorkerGrowableQueue()
+
+	workers := make([]worker, 1000)
+	for i := range workers {
+		workers[i] = &Worker{lastUpdatedTime: time.Now()}
+		assert.NoError(t, q.insert(workers[i]), "Enqueue error")
+	}
+	assert.EqualValues(t, 1000, q.len(), "Len error")
+	assert.EqualValues(t, 1024, len(q.items), "items should double")
+
+	// FIFO
+	for i := 0; i < 990; i++ {
+		assert.Same(t, workers[i], q.detach(), "Dequeue order error")
+	}
+	assert.EqualValues(t, 10, q.len(), "Len error")
+	assert.EqualValues(t, 32, len(q.items), "items should shrink with the idle workers")
+
+	for i := 990; i < 1000; i++ {
+		assert.Same(t, workers[i], q.detach(), "Dequeue order error")
+	}
+	assert.EqualValues(t, minGrowableQueueSize, len(q.items), "items should not shrink below the minimum")
+}
+
+func TestGrowableQueueWrapAround(t *testing.T) {
+	q := newWorkerGrowableQueue()
+
+	// head 在中間時加倍，順序不變
+	for i := 0; i < 10; i++ {
+		_ = q.insert(&Worker{})
+	}
+	for i := 0; i < 8; i++ {
+		_ = q.detach()
+	}
+	workers := make([]worker, 20)
+	for i := range workers {
+		workers[i] = &Worker{}
+		_ = q.insert(workers[i])
+	}
+	_ = q.detach()
+	_ = q.detach()
+	for i := range workers {
+		assert.Same(t, workers[i], q.detach(), "Dequeue order error")
+	}
+	assert.True(t, q.isEmpty())
+}
+
+func TestGrowableQueueRefresh(t *testing.T) {
+	q := newWorkerGrowableQueue()
+	now := time.Now()
+
+	// 前 6 個過期
+	for i := 0; i < 10; i++ {
+		updated := now
+		if i < 6 {
+			updated = now.Add(-time.Minute)
+		}
+		_ = q.insert(&Worker{lastUpdatedTime: updated})
+	}
+	assert.EqualValues(t, 6, q.binarySearch(now.Add(-time.Second)))
+	assert.EqualValues(t, 0, q.binarySearch(now.Add(-2*time.Minute)))
+	assert.EqualValues(t, 10, q.binarySearch(now.Add(time.Second)))
+
+	// 每次最多清理 2 個
+	assert.Len(t, q.refresh(time.Second, 0, 2), 2)
+	assert.EqualValues(t, 8, q.len())
+
+	// 至少保留 5 個
+	assert.Len(t, q.refresh(time.Second, 5, 0), 3)
+	assert.EqualValues(t, 5, q.len())
+
+	assert.Len(t, q.refresh(time.Second, 0, 0), 1)
+	assert.EqualValues(t, 4, q.len())
+	assert.Nil(t, q.refresh(time.Second, 0, 0))
+}
+
+func TestGrowableQueueReset(t *testing.T) {
+	q := newWorkerGrowableQueue()
+	workers := make([]*Worker, 100)
+	for i := range workers {
+		workers[i] = &Worker{task: make(chan func(), 1)}
+		_ = q.insert(workers[i])
+	}
+
+	q.reset()
+	assert.EqualValues(t, 0, q.len())
+	assert.Nil(t, q.items, "space should be released")
+	for _, w := range workers {
+		assert.Nil(t, <-w.task, "worker should be finished")
+	}
+
+	// 重設後還可以繼續使用
+	assert.NoError(t, q.insert(&Worker{}))
+	assert.EqualValues(t, 1, q.len())
+}
+
+func TestUnlimitedPoolUsesGrowableQueue(t *testing.T) {
+	p, _ := NewPool(0)
+	defer p.Release()
+	assert.IsType(t, &growableQueue{}, p.workers)
+
+	_ = p.Warmup(100)
+	assert.EqualValues(t, 100, p.Running())
+	assert.EqualValues(t, 128, len(p.workers.(*growableQueue).items))
+
+	// 預設的 Pool 也沒有上限
+	p3 := NewDefaultPool()
+	defer p3.Release()
+	assert.Equal(t, -1, p3.Cap())
+	assert.IsType(t, &growableQueue{}, p3.workers)
+
+	p2, _ := NewPool(10)
+	defer p2.Release()
+	assert.IsType(t, &circularQueue{}, p2.workers)
+}